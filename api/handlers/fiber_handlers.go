@@ -1,10 +1,15 @@
 package handlers
 
 import (
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
+	"github.com/alper.meric/messaging-system/logging"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/repository"
 	"github.com/alper.meric/messaging-system/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,11 +30,11 @@ func NewMessageController(
 
 // ServiceControl handles service control actions for Fiber
 // @Summary Controls the message service
-// @Description Starts or stops the message sending service
+// @Description Starts, stops, pauses, resumes or drains the message sending service, or processes a batch right away with run-once
 // @Tags service
 // @Accept json
 // @Produce json
-// @Param action query string true "Action to perform (start/stop)"
+// @Param action query string true "Action to perform (start/stop/pause/resume/drain/run-once)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
@@ -41,7 +46,7 @@ func (mc *MessageController) ServiceControl(c *fiber.Ctx) error {
 	if action == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "action parameter is required (start/stop)",
+			"error":   "action parameter is required (start/stop/pause/resume/drain/run-once)",
 		})
 	}
 
@@ -53,17 +58,34 @@ func (mc *MessageController) ServiceControl(c *fiber.Ctx) error {
 		err = mc.messageService.Start()
 		message = "Message service started successfully"
 	case "stop":
-		err = mc.messageService.Stop()
+		err = mc.messageService.Stop(c.UserContext())
 		message = "Message service stopped successfully"
+	case "pause":
+		err = mc.messageService.Pause()
+		message = "Message service paused successfully"
+	case "resume":
+		err = mc.messageService.Resume()
+		message = "Message service resumed successfully"
+	case "drain":
+		err = mc.messageService.Drain()
+		message = "Message service is draining the queue"
+	case "run-once":
+		summary := mc.messageService.RunOnce(c.UserContext())
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"message": "Message processing run completed",
+			"running": mc.messageService.Status(),
+			"summary": summary,
+		})
 	default:
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
-			"error":   "Invalid action. Use 'start' or 'stop'",
+			"error":   "Invalid action. Use 'start', 'stop', 'pause', 'resume', 'drain' or 'run-once'",
 		})
 	}
 
 	if err != nil {
-		log.Printf("Service control error: %v", err)
+		logging.FromContext(c.UserContext()).Error("service control failed", "action", action, logging.KeyError, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
 			"error":   err.Error(),
@@ -79,77 +101,507 @@ func (mc *MessageController) ServiceControl(c *fiber.Ctx) error {
 
 // ServiceStatus retrieves the current status of the message service
 // @Summary Gets service status
-// @Description Retrieves the current running status of the message service
+// @Description Retrieves the running status and runtime statistics of the message service
 // @Tags service
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /service/status [get]
 func (mc *MessageController) ServiceStatus(c *fiber.Ctx) error {
+	status := mc.messageService.GetStatus(c.UserContext())
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": true,
-		"running": mc.messageService.Status(),
+		"running": status.IsRunning,
+		"status":  status,
 	})
 }
 
-// GetSentMessages lists sent messages using Fiber
-// @Summary Retrieves sent messages
-// @Description Gets a list of sent messages with pagination
+// ListMessages lists messages using Fiber
+// @Summary Retrieves messages
+// @Description Gets a filtered and sorted list of messages with pagination. Without a status only sent messages are listed, most recently sent first.
 // @Tags messages
 // @Accept json
 // @Produce json
+// @Param status query string false "Message status: sent (default), unsent, failed, cancelled or all"
+// @Param phone query string false "Exact phone number"
+// @Param phonePrefix query string false "Phone number prefix"
+// @Param content query string false "Case-insensitive content substring"
+// @Param externalId query string false "External message ID"
+// @Param createdFrom query string false "Created at or after (RFC 3339)"
+// @Param createdTo query string false "Created before (RFC 3339)"
+// @Param sentFrom query string false "Sent at or after (RFC 3339)"
+// @Param sentTo query string false "Sent before (RFC 3339)"
+// @Param sort query string false "Sort field: createdAt, sentAt or id (default: sentAt for sent messages, createdAt otherwise)"
+// @Param order query string false "Sort direction: asc or desc (default: desc)"
 // @Param page query int false "Page number (default: 1)"
+// @Param cursor query string false "Cursor from the next/prev field of a previous response, replaces page"
+// @Param count query string false "Total count: exact, estimated or none (default: exact with page, none with cursor)"
 // @Param limit query int false "Items per page (default: 10)"
 // @Success 200 {object} models.MessageListResponse
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /messages [get]
-func (mc *MessageController) GetSentMessages(c *fiber.Ctx) error {
-	// Parse pagination parameters
-	page := 1
-	limit := 10
-	var err error
+func (mc *MessageController) ListMessages(c *fiber.Ctx) error {
+	filter, err := parseMessageFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
 
-	if c.Query("page") != "" {
-		page, err = strconv.Atoi(c.Query("page"))
-		if err != nil || page < 1 {
-			page = 1
+	page, err := mc.messageService.ListMessages(c.UserContext(), filter)
+	if err != nil {
+		logging.FromContext(c.UserContext()).Error("failed to retrieve messages", logging.KeyError, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"error":   "Failed to retrieve messages",
+		})
+	}
+
+	// Create response
+	response := models.MessageListResponse{
+		Success:        true,
+		Messages:       page.Messages,
+		Limit:          filter.Limit,
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
+	}
+
+	if filter.Cursor == nil {
+		response.Page = filter.Page
+	}
+
+	// Calculate total pages
+	if page.Total != nil {
+		pages := *page.Total / filter.Limit
+		if *page.Total%filter.Limit > 0 {
+			pages++
 		}
+		response.Pages = &pages
 	}
 
-	if c.Query("limit") != "" {
-		limit, err = strconv.Atoi(c.Query("limit"))
-		if err != nil || limit < 1 || limit > 100 {
-			limit = 10
+	if page.Next != nil {
+		response.Next = page.Next.Encode()
+	}
+	if page.Prev != nil {
+		response.Prev = page.Prev.Encode()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// GetMessage retrieves a single message using Fiber
+// @Summary Retrieves a message
+// @Description Gets a message by its ID
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} models.MessageDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/{id} [get]
+func (mc *MessageController) GetMessage(c *fiber.Ctx) error {
+	id, ok := messageID(c)
+	if !ok {
+		return invalidMessageID(c)
+	}
+
+	message, err := mc.messageService.GetMessage(c.UserContext(), id)
+	return mc.messageDetail(c, message, err)
+}
+
+// GetMessageByExternalID retrieves a single message by external ID using Fiber
+// @Summary Retrieves a message by external ID
+// @Description Gets a message by the ID the webhook returned for it, looked up in the cache first
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param externalId path string true "External message ID"
+// @Success 200 {object} models.MessageDetailResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/by-external-id/{externalId} [get]
+func (mc *MessageController) GetMessageByExternalID(c *fiber.Ctx) error {
+	message, err := mc.messageService.GetMessageByExternalID(c.UserContext(), c.Params("externalId"))
+	return mc.messageDetail(c, message, err)
+}
+
+// CancelMessage cancels a queued message using Fiber
+// @Summary Cancels a message
+// @Description Stops a message from being sent, as long as it has not been claimed for sending yet
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} models.MessageDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/{id}/cancel [post]
+func (mc *MessageController) CancelMessage(c *fiber.Ctx) error {
+	id, ok := messageID(c)
+	if !ok {
+		return invalidMessageID(c)
+	}
+
+	message, err := mc.messageService.CancelMessage(c.UserContext(), id)
+	if err != nil {
+		return messageChangeError(c, "cancel", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.MessageDetailResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+// CreateMessage queues a new message using Fiber
+// @Summary Creates a message
+// @Description Queues a message for sending. The phone number is normalised to E.164, numbers without a country code are read in the default region; impossible numbers are rejected. Content is stored as written and sent transliterated to GSM-7 when transliteration is enabled.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param message body models.MessageCreateRequest true "New message"
+// @Success 201 {object} models.MessageDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages [post]
+func (mc *MessageController) CreateMessage(c *fiber.Ctx) error {
+	var request models.MessageCreateRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("invalid request body: %v", err),
+		})
+	}
+
+	message, err := mc.messageService.CreateMessage(c.UserContext(), models.Message{
+		Content:         request.Content,
+		PhoneNumber:     request.PhoneNumber,
+		Category:        request.Category,
+		Priority:        request.Priority,
+		ScheduledAt:     request.ScheduledAt,
+		Transliteration: request.Transliteration,
+	})
+	if err != nil {
+		return messageChangeError(c, "create", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.MessageDetailResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+// PreviewMessage describes how content would be sent using Fiber
+// @Summary Previews message content
+// @Description Detects the SMS encoding of the content (GSM-7, or UCS-2 as soon as a character is outside the GSM alphabet), counts the concatenated segments it is sent in and checks it against the configured limits. Content that fits GSM-7 once transliterated is described as it is sent. Nothing is stored.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param message body models.MessagePreviewRequest true "Content to preview"
+// @Success 200 {object} models.MessagePreviewResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /messages/preview [post]
+func (mc *MessageController) PreviewMessage(c *fiber.Ctx) error {
+	var request models.MessagePreviewRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   fmt.Sprintf("invalid request body: %v", err),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.MessagePreviewResponse{
+		Success: true,
+		Preview: mc.messageService.PreviewContent(request.Content, request.Transliteration),
+	})
+}
+
+// UpdateMessage changes a queued message using Fiber
+// @Summary Updates a message
+// @Description Changes the content, recipient or schedule of a message, as long as it has not been claimed for sending yet
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Param message body models.MessageUpdateRequest true "Fields to change"
+// @Success 200 {object} models.MessageDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/{id} [patch]
+func (mc *MessageController) UpdateMessage(c *fiber.Ctx) error {
+	id, ok := messageID(c)
+	if !ok {
+		return invalidMessageID(c)
+	}
+
+	update, err := parseMessageUpdate(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	message, err := mc.messageService.UpdateMessage(c.UserContext(), id, update)
+	if err != nil {
+		return messageChangeError(c, "update", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(models.MessageDetailResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+// DeleteMessage deletes a queued or cancelled message using Fiber
+// @Summary Deletes a message
+// @Description Deletes a message that has not been sent, as long as it has not been claimed for sending yet
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/{id} [delete]
+func (mc *MessageController) DeleteMessage(c *fiber.Ctx) error {
+	id, ok := messageID(c)
+	if !ok {
+		return invalidMessageID(c)
+	}
+
+	if err := mc.messageService.DeleteMessage(c.UserContext(), id); err != nil {
+		return messageChangeError(c, "delete", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Message deleted successfully",
+	})
+}
+
+// ResendMessage sends a copy of a message using Fiber
+// @Summary Resends a message
+// @Description Queues a copy of a sent or failed message and sends it right away instead of waiting for the next run, unless the service is stopped or paused or outside its sending windows; such copies are queued with high priority. The copy references the original through parentId and stays queued when it could not be delivered.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Message ID"
+// @Success 201 {object} models.MessageDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /messages/{id}/resend [post]
+func (mc *MessageController) ResendMessage(c *fiber.Ctx) error {
+	id, ok := messageID(c)
+	if !ok {
+		return invalidMessageID(c)
+	}
+
+	message, err := mc.messageService.ResendMessage(c.UserContext(), id)
+	if err != nil {
+		return messageChangeError(c, "resend", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.MessageDetailResponse{
+		Success: true,
+		Message: message,
+	})
+}
+
+// messageID reads the message ID path parameter
+func messageID(c *fiber.Ctx) (int, bool) {
+	id, err := c.ParamsInt("id")
+	return id, err == nil && id >= 1
+}
+
+// invalidMessageID writes the response for a malformed message ID
+func invalidMessageID(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error":   "Invalid message ID",
+	})
+}
+
+// messageChangeError writes the response of a failed change to a message
+func messageChangeError(c *fiber.Ctx, action string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrMessageNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message not found",
+		})
+	case errors.Is(err, repository.ErrMessageNotEditable), errors.Is(err, repository.ErrMessageChanged),
+		errors.Is(err, services.ErrMessageNotResendable):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidMessage):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"error":   err.Error(),
+		})
+	}
+
+	logging.FromContext(c.UserContext()).Error("failed to change message", "action", action, logging.KeyError, err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"success": false,
+		"error":   fmt.Sprintf("Failed to %s message", action),
+	})
+}
+
+// parseMessageUpdate builds the message update from the request body
+func parseMessageUpdate(c *fiber.Ctx) (repository.MessageUpdate, error) {
+	var request models.MessageUpdateRequest
+	if err := json.Unmarshal(c.Body(), &request); err != nil {
+		return repository.MessageUpdate{}, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	update := repository.MessageUpdate{
+		Content:         request.Content,
+		PhoneNumber:     request.PhoneNumber,
+		Category:        request.Category,
+		Priority:        request.Priority,
+		Transliteration: request.Transliteration,
+	}
+
+	switch value := string(request.ScheduledAt); value {
+	case "":
+	case "null":
+		update.ClearSchedule = true
+	default:
+		var scheduledAt time.Time
+		if err := json.Unmarshal(request.ScheduledAt, &scheduledAt); err != nil {
+			return update, fmt.Errorf("invalid scheduledAt %s, use an RFC 3339 timestamp", value)
 		}
+		update.ScheduledAt = &scheduledAt
 	}
 
-	// Get sent messages from service instead of repository
-	messages, total, err := mc.messageService.GetSentMessages(page, limit)
+	return update, nil
+}
+
+// messageDetail writes the response of a single message lookup, including the
+// resends of the message
+func (mc *MessageController) messageDetail(c *fiber.Ctx, message models.Message, err error) error {
+	var resends []models.Message
+	if err == nil {
+		resends, err = mc.messageService.GetResends(c.UserContext(), message.ID)
+	}
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"error":   "Message not found",
+		})
+	}
 	if err != nil {
-		log.Printf("Error retrieving sent messages: %v", err)
+		logging.FromContext(c.UserContext()).Error("failed to retrieve message", logging.KeyError, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
-			"error":   "Failed to retrieve messages",
+			"error":   "Failed to retrieve message",
 		})
 	}
 
-	// Calculate total pages
-	pages := total / limit
-	if total%limit > 0 {
-		pages++
+	return c.Status(fiber.StatusOK).JSON(models.MessageDetailResponse{
+		Success: true,
+		Message: message,
+		Resends: resends,
+	})
+}
+
+// parseMessageFilter builds the message filter from the query parameters
+func parseMessageFilter(c *fiber.Ctx) (repository.MessageFilter, error) {
+	filter := repository.MessageFilter{
+		Status:        c.Query("status", models.MessageStatusSent),
+		PhoneNumber:   c.Query("phone"),
+		PhonePrefix:   c.Query("phonePrefix"),
+		Content:       c.Query("content"),
+		ExternalMsgID: c.Query("externalId"),
+		SortBy:        c.Query("sort"),
+		Page:          1,
+		Limit:         10,
 	}
 
-	// Create response
-	response := models.MessageListResponse{
-		Success:  true,
-		Messages: messages,
-		Page:     page,
-		Limit:    limit,
-		Total:    total,
-		Pages:    pages,
+	if filter.Status == "all" {
+		filter.Status = ""
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	// Sent messages are listed most recently sent first, like before filtering existed
+	if filter.SortBy == "" && filter.Status == models.MessageStatusSent {
+		filter.SortBy = repository.SortBySentAt
+	}
+
+	switch c.Query("order", "desc") {
+	case "desc":
+		filter.SortDesc = true
+	case "asc":
+	default:
+		return filter, fmt.Errorf("invalid order %q, use asc or desc", c.Query("order"))
+	}
+
+	// A cursor replaces the page number and brings its sort order along
+	filter.Count = repository.CountExact
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := repository.DecodeCursor(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+		filter.Count = repository.CountNone
+
+		if c.Query("sort") == "" {
+			filter.SortBy = cursor.SortBy
+		}
+		if c.Query("order") == "" {
+			filter.SortDesc = cursor.SortDesc
+		}
+	}
+	if count := c.Query("count"); count != "" {
+		filter.Count = count
+	}
+
+	// Invalid pagination parameters fall back to the defaults
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page >= 1 {
+		filter.Page = page
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit >= 1 && limit <= 100 {
+		filter.Limit = limit
+	}
+
+	for _, timeParam := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"createdFrom", &filter.CreatedFrom},
+		{"createdTo", &filter.CreatedTo},
+		{"sentFrom", &filter.SentFrom},
+		{"sentTo", &filter.SentTo},
+	} {
+		param, target := timeParam.name, timeParam.target
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s %q, use an RFC 3339 timestamp", param, value)
+		}
+		*target = &t
+	}
+
+	return filter, filter.Validate()
 }
 
 // Artık doğrudan controller kullanıldığı için uyumluluk fonksiyonlarına ihtiyaç kalmadı.
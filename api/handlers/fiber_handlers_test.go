@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
 	mockservices "github.com/alper.meric/messaging-system/mocks/services"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -64,7 +72,15 @@ func (suite *MessageControllerTestSuite) SetupTest() {
 	// Controller rotalarını kaydet
 	suite.app.Post("/api/service", suite.controller.ServiceControl)
 	suite.app.Get("/api/service/status", suite.controller.ServiceStatus)
-	suite.app.Get("/api/messages", suite.controller.GetSentMessages)
+	suite.app.Get("/api/messages", suite.controller.ListMessages)
+	suite.app.Post("/api/messages", suite.controller.CreateMessage)
+	suite.app.Post("/api/messages/preview", suite.controller.PreviewMessage)
+	suite.app.Get("/api/messages/by-external-id/:externalId", suite.controller.GetMessageByExternalID)
+	suite.app.Get("/api/messages/:id", suite.controller.GetMessage)
+	suite.app.Patch("/api/messages/:id", suite.controller.UpdateMessage)
+	suite.app.Delete("/api/messages/:id", suite.controller.DeleteMessage)
+	suite.app.Post("/api/messages/:id/cancel", suite.controller.CancelMessage)
+	suite.app.Post("/api/messages/:id/resend", suite.controller.ResendMessage)
 }
 
 // TestServiceControl, servis kontrol endpointlerini test eder
@@ -88,7 +104,7 @@ func (suite *MessageControllerTestSuite) TestServiceControl() {
 
 	// Stop action testi
 	suite.mockService.EXPECT().Status().Return(true)
-	suite.mockService.EXPECT().Stop().Return(nil)
+	suite.mockService.EXPECT().Stop(mock.Anything).Return(nil)
 
 	req = httptest.NewRequest(http.MethodPost, "/api/service?action=stop", nil)
 	resp, err = suite.app.Test(req)
@@ -103,10 +119,66 @@ func (suite *MessageControllerTestSuite) TestServiceControl() {
 	assert.Contains(suite.T(), result["message"].(string), "stopped")
 }
 
+// TestServiceControlModes, duraklatma, devam ettirme ve boşaltma eylemlerini test eder
+func (suite *MessageControllerTestSuite) TestServiceControlModes() {
+	suite.mockService.EXPECT().Pause().Return(nil).Once()
+	suite.mockService.EXPECT().Resume().Return(nil).Once()
+	suite.mockService.EXPECT().Drain().Return(errors.New("message service is not running")).Once()
+	suite.mockService.EXPECT().Status().Return(true)
+
+	for action, status := range map[string]int{
+		"pause":  http.StatusOK,
+		"resume": http.StatusOK,
+		"drain":  http.StatusInternalServerError,
+		"freeze": http.StatusBadRequest,
+	} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/service?action="+action, nil))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), status, resp.StatusCode, action)
+
+		var result map[string]interface{}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &result)
+		assert.Equal(suite.T(), status == http.StatusOK, result["success"].(bool), action)
+	}
+}
+
+// TestServiceControlRunOnce, anlık işleme çalıştırmasının özetini döndürdüğünü test eder
+func (suite *MessageControllerTestSuite) TestServiceControlRunOnce() {
+	suite.mockService.EXPECT().RunOnce(mock.Anything).Return(models.RunSummary{Sent: 2, Failed: 1}).Once()
+	suite.mockService.EXPECT().Status().Return(false)
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/service?action=run-once", nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Success bool              `json:"success"`
+		Running bool              `json:"running"`
+		Summary models.RunSummary `json:"summary"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+
+	assert.True(suite.T(), result.Success)
+	assert.False(suite.T(), result.Running, "Zamanlayıcı başlatılmamalı")
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2, Failed: 1}, result.Summary)
+}
+
 // TestServiceStatus, servis durum endpointini test eder
 func (suite *MessageControllerTestSuite) TestServiceStatus() {
 	// Çalışırken durumu
-	suite.mockService.EXPECT().Status().Return(true).Once()
+	lastRun := time.Now().Add(-1 * time.Minute)
+	queueDepth := 3
+	suite.mockService.EXPECT().GetStatus(mock.Anything).Return(models.ServiceStatus{
+		IsRunning:   true,
+		Mode:        models.ServiceModePaused,
+		LastRunTime: &lastRun,
+		LastRun:     models.RunSummary{Sent: 2, Failed: 1},
+		Total:       models.RunSummary{Sent: 5, Failed: 1, Skipped: 1},
+		QueueDepth:  &queueDepth,
+		LastError:   "webhook unavailable",
+	}).Once()
 
 	req := httptest.NewRequest(http.MethodGet, "/api/service/status", nil)
 	resp, err := suite.app.Test(req)
@@ -114,15 +186,26 @@ func (suite *MessageControllerTestSuite) TestServiceStatus() {
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
-	var result map[string]interface{}
+	var result struct {
+		Success bool                 `json:"success"`
+		Running bool                 `json:"running"`
+		Status  models.ServiceStatus `json:"status"`
+	}
 	body, _ := io.ReadAll(resp.Body)
 	json.Unmarshal(body, &result)
 
-	assert.True(suite.T(), result["success"].(bool))
-	assert.True(suite.T(), result["running"].(bool))
+	assert.True(suite.T(), result.Success)
+	assert.True(suite.T(), result.Running)
+	assert.True(suite.T(), result.Status.IsRunning)
+	assert.Equal(suite.T(), models.ServiceModePaused, result.Status.Mode)
+	assert.Equal(suite.T(), 2, result.Status.LastRun.Sent)
+	assert.Equal(suite.T(), 5, result.Status.Total.Sent)
+	assert.Equal(suite.T(), 3, *result.Status.QueueDepth)
+	assert.Equal(suite.T(), "webhook unavailable", result.Status.LastError)
+	assert.NotNil(suite.T(), result.Status.LastRunTime)
 
 	// Dururken durumu
-	suite.mockService.EXPECT().Status().Return(false).Once()
+	suite.mockService.EXPECT().GetStatus(mock.Anything).Return(models.ServiceStatus{IsRunning: false}).Once()
 
 	req = httptest.NewRequest(http.MethodGet, "/api/service/status", nil)
 	resp, err = suite.app.Test(req)
@@ -130,17 +213,32 @@ func (suite *MessageControllerTestSuite) TestServiceStatus() {
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
+	result.Running = true
 	body, _ = io.ReadAll(resp.Body)
 	json.Unmarshal(body, &result)
 
-	assert.True(suite.T(), result["success"].(bool))
-	assert.False(suite.T(), result["running"].(bool))
+	assert.True(suite.T(), result.Success)
+	assert.False(suite.T(), result.Running)
 }
 
-// TestGetSentMessages, gönderilmiş mesajları getirme endpointini test eder
-func (suite *MessageControllerTestSuite) TestGetSentMessages() {
-	// Mesajları getirme başarılı senaryosu
-	suite.mockService.EXPECT().GetSentMessages(1, 10).Return(suite.testMessages, len(suite.testMessages), nil)
+// TestListMessages, varsayılan olarak gönderilmiş mesajları getiren listeleme endpointini test eder
+func (suite *MessageControllerTestSuite) TestListMessages() {
+	// Filtre verilmezse eski davranış korunur: gönderilmiş mesajlar, en son gönderilen önce
+	expected := repository.MessageFilter{
+		Status:   models.MessageStatusSent,
+		SortBy:   repository.SortBySentAt,
+		SortDesc: true,
+		Page:     1,
+		Limit:    10,
+		Count:    repository.CountExact,
+	}
+	total := len(suite.testMessages)
+	next := &repository.Cursor{SortBy: repository.SortBySentAt, SortDesc: true, ID: suite.testMessages[1].ID}
+	suite.mockService.EXPECT().ListMessages(mock.Anything, expected).Return(repository.MessagePage{
+		Messages: suite.testMessages,
+		Total:    &total,
+		Next:     next,
+	}, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/messages?page=1&limit=10", nil)
 	resp, err := suite.app.Test(req)
@@ -153,9 +251,384 @@ func (suite *MessageControllerTestSuite) TestGetSentMessages() {
 	json.Unmarshal(body, &result)
 
 	assert.True(suite.T(), result.Success)
-	assert.Equal(suite.T(), len(suite.testMessages), result.Total)
+	assert.Equal(suite.T(), total, *result.Total)
+	assert.Equal(suite.T(), 1, *result.Pages)
+	assert.Equal(suite.T(), 1, result.Page)
 	assert.Equal(suite.T(), len(suite.testMessages), len(result.Messages))
 	assert.Equal(suite.T(), suite.testMessages[0].ID, result.Messages[0].ID)
+	assert.Equal(suite.T(), next.Encode(), result.Next)
+	assert.Empty(suite.T(), result.Prev)
+}
+
+// TestListMessagesCursor, imleç ile sayfalamayı test eder
+func (suite *MessageControllerTestSuite) TestListMessagesCursor() {
+	sentAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	cursor := repository.Cursor{SortBy: repository.SortBySentAt, SortDesc: true, Time: &sentAt, ID: 7}
+
+	var got repository.MessageFilter
+	suite.mockService.EXPECT().ListMessages(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, filter repository.MessageFilter) { got = filter }).
+		Return(repository.MessagePage{Messages: suite.testMessages, Prev: &cursor}, nil)
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodGet, "/api/messages?limit=2&cursor="+cursor.Encode(), nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// İmleç sıralamayı belirler, toplam sayı varsayılan olarak hesaplanmaz
+	assert.Equal(suite.T(), cursor.ID, got.Cursor.ID)
+	assert.True(suite.T(), sentAt.Equal(*got.Cursor.Time))
+	assert.Equal(suite.T(), repository.SortBySentAt, got.SortBy)
+	assert.True(suite.T(), got.SortDesc)
+	assert.Equal(suite.T(), repository.CountNone, got.Count)
+	assert.Equal(suite.T(), 2, got.Limit)
+
+	var result map[string]interface{}
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.NotContains(suite.T(), result, "total")
+	assert.NotContains(suite.T(), result, "pages")
+	assert.NotContains(suite.T(), result, "page")
+	assert.NotContains(suite.T(), result, "next")
+	assert.Equal(suite.T(), cursor.Encode(), result["prev"])
+
+	// Tahmini toplam istenebilir
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodGet, "/api/messages?count=estimated&cursor="+cursor.Encode(), nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), repository.CountEstimated, got.Count)
+}
+
+// TestListMessagesFilters, sorgu parametrelerinin filtreye dönüştürülmesini test eder
+func (suite *MessageControllerTestSuite) TestListMessagesFilters() {
+	createdFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sentTo := time.Date(2025, 2, 1, 12, 30, 0, 0, time.FixedZone("", 3*60*60))
+
+	var got repository.MessageFilter
+	suite.mockService.EXPECT().ListMessages(mock.Anything, mock.Anything).
+		Run(func(_ context.Context, filter repository.MessageFilter) { got = filter }).
+		Return(repository.MessagePage{Messages: []models.Message{}}, nil)
+
+	query := url.Values{
+		"status":      {"failed"},
+		"phone":       {"+905551112233"},
+		"phonePrefix": {"+90555"},
+		"content":     {"kod"},
+		"externalId":  {"ext-1"},
+		"createdFrom": {"2025-01-01T00:00:00Z"},
+		"sentTo":      {"2025-02-01T12:30:00+03:00"},
+		"sort":        {"id"},
+		"order":       {"asc"},
+		"page":        {"2"},
+		"limit":       {"20"},
+	}
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodGet, "/api/messages?"+query.Encode(), nil))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Equal(suite.T(), models.MessageStatusFailed, got.Status)
+	assert.Equal(suite.T(), "+905551112233", got.PhoneNumber)
+	assert.Equal(suite.T(), "+90555", got.PhonePrefix)
+	assert.Equal(suite.T(), "kod", got.Content)
+	assert.Equal(suite.T(), "ext-1", got.ExternalMsgID)
+	assert.True(suite.T(), createdFrom.Equal(*got.CreatedFrom))
+	assert.True(suite.T(), sentTo.Equal(*got.SentTo))
+	assert.Nil(suite.T(), got.CreatedTo)
+	assert.Nil(suite.T(), got.SentFrom)
+	assert.Equal(suite.T(), repository.SortByID, got.SortBy)
+	assert.False(suite.T(), got.SortDesc)
+	assert.Equal(suite.T(), 2, got.Page)
+	assert.Equal(suite.T(), 20, got.Limit)
+
+	// "all" durum filtresini kaldırır, sıralama oluşturulma zamanına göre yapılır
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodGet, "/api/messages?status=all", nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	assert.Empty(suite.T(), got.Status)
+	assert.Empty(suite.T(), got.SortBy)
+	assert.True(suite.T(), got.SortDesc)
+}
+
+// TestListMessagesInvalidParameters, hatalı sorgu parametrelerinin 400 döndürdüğünü test eder
+func (suite *MessageControllerTestSuite) TestListMessagesInvalidParameters() {
+	for _, query := range []string{
+		"status=pending",
+		"sort=content",
+		"order=up",
+		"createdFrom=yesterday",
+		"sentFrom=2025-02-01T00:00:00Z&sentTo=2025-01-01T00:00:00Z",
+		"cursor=not-a-cursor",
+		"count=some",
+		// İmleç başka bir sıralamaya ait
+		"sort=id&cursor=" + repository.Cursor{SortBy: repository.SortBySentAt, ID: 1}.Encode(),
+	} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodGet, "/api/messages?"+query, nil))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, query)
+
+		var result map[string]interface{}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &result)
+		assert.False(suite.T(), result["success"].(bool), query)
+		assert.NotEmpty(suite.T(), result["error"], query)
+	}
+
+	suite.mockService.AssertNotCalled(suite.T(), "ListMessages", mock.Anything, mock.Anything)
+}
+
+// TestGetMessage, tekil mesaj endpointlerini test eder
+func (suite *MessageControllerTestSuite) TestGetMessage() {
+	message := suite.testMessages[0]
+	suite.mockService.EXPECT().GetMessage(mock.Anything, message.ID).Return(message, nil)
+	suite.mockService.EXPECT().GetMessage(mock.Anything, 404).Return(models.Message{}, repository.ErrMessageNotFound)
+	suite.mockService.EXPECT().GetMessageByExternalID(mock.Anything, "ext-1").Return(message, nil)
+	suite.mockService.EXPECT().GetMessageByExternalID(mock.Anything, "ext-404").Return(models.Message{}, fmt.Errorf("lookup: %w", repository.ErrMessageNotFound))
+	suite.mockService.EXPECT().GetMessageByExternalID(mock.Anything, "ext-500").Return(models.Message{}, errors.New("database is down"))
+	parentID := message.ID
+	resend := models.Message{ID: 3, PhoneNumber: message.PhoneNumber, Content: message.Content, ParentID: &parentID}
+	suite.mockService.EXPECT().GetResends(mock.Anything, message.ID).Return([]models.Message{resend}, nil)
+
+	for _, path := range []string{fmt.Sprintf("/api/messages/%d", message.ID), "/api/messages/by-external-id/ext-1"} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusOK, resp.StatusCode, path)
+
+		var result models.MessageDetailResponse
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &result)
+		assert.True(suite.T(), result.Success)
+		assert.Equal(suite.T(), message.ID, result.Message.ID)
+		assert.Equal(suite.T(), message.Content, result.Message.Content)
+		// Yeniden gönderimler orijinal mesajın detayında listelenir
+		if assert.Len(suite.T(), result.Resends, 1) {
+			assert.Equal(suite.T(), resend.ID, result.Resends[0].ID)
+			assert.Equal(suite.T(), message.ID, *result.Resends[0].ParentID)
+		}
+	}
+
+	// Hatalar standart hata yapısında döner
+	for path, status := range map[string]int{
+		"/api/messages/404":                    http.StatusNotFound,
+		"/api/messages/by-external-id/ext-404": http.StatusNotFound,
+		"/api/messages/by-external-id/ext-500": http.StatusInternalServerError,
+		"/api/messages/abc":                    http.StatusBadRequest,
+		"/api/messages/0":                      http.StatusBadRequest,
+	} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), status, resp.StatusCode, path)
+
+		var result map[string]interface{}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &result)
+		assert.False(suite.T(), result["success"].(bool), path)
+		assert.NotEmpty(suite.T(), result["error"], path)
+	}
+}
+
+// TestUpdateMessage, mesaj güncelleme endpointini test eder
+func (suite *MessageControllerTestSuite) TestUpdateMessage() {
+	content := "changed"
+	scheduledAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := models.Message{ID: 3, PhoneNumber: "+90123456789", Content: content, ScheduledAt: &scheduledAt}
+
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 3, mock.MatchedBy(func(update repository.MessageUpdate) bool {
+		return *update.Content == content && update.PhoneNumber == nil &&
+			update.ScheduledAt.Equal(scheduledAt) && !update.ClearSchedule
+	})).Return(updated, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/messages/3",
+		strings.NewReader(`{"content":"changed","scheduledAt":"2030-01-02T03:04:05Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := suite.app.Test(req)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var result models.MessageDetailResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.True(suite.T(), result.Success)
+	assert.Equal(suite.T(), content, result.Message.Content)
+
+	// null zamanlamayı kaldırır
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 3, repository.MessageUpdate{ClearSchedule: true}).Return(updated, nil).Once()
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPatch, "/api/messages/3", strings.NewReader(`{"scheduledAt":null}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// Kategori ve öncelik değiştirilebilir
+	promotional := models.MessageCategoryPromotional
+	low := models.MessagePriorityLow
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 3, repository.MessageUpdate{Category: &promotional, Priority: &low}).Return(updated, nil).Once()
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPatch, "/api/messages/3", strings.NewReader(`{"category":"promotional","priority":-1}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// Harf çevirisi mesaj bazında değiştirilebilir
+	off := models.MessageTransliterationOff
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 3, repository.MessageUpdate{Transliteration: &off}).Return(updated, nil).Once()
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPatch, "/api/messages/3", strings.NewReader(`{"transliteration":"off"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	// Geçersiz gövdeler servise ulaşmadan reddedilir
+	for _, body := range []string{`not json`, `{"scheduledAt":"tomorrow"}`, `{"content":5}`} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodPatch, "/api/messages/3", strings.NewReader(body)))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
+
+// TestMessageChangeErrors, değişiklik endpointlerinin hata eşlemesini test eder
+func (suite *MessageControllerTestSuite) TestMessageChangeErrors() {
+	notEditable := fmt.Errorf("%w: message 5 is claimed for sending", repository.ErrMessageNotEditable)
+	suite.mockService.EXPECT().CancelMessage(mock.Anything, 4).Return(models.Message{}, repository.ErrMessageNotFound)
+	suite.mockService.EXPECT().CancelMessage(mock.Anything, 5).Return(models.Message{}, notEditable)
+	suite.mockService.EXPECT().DeleteMessage(mock.Anything, 5).Return(notEditable)
+	suite.mockService.EXPECT().DeleteMessage(mock.Anything, 6).Return(errors.New("database is down"))
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 5, mock.Anything).Return(models.Message{}, notEditable)
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 7, mock.Anything).Return(models.Message{}, fmt.Errorf("%w: nothing to update", services.ErrInvalidMessage))
+	suite.mockService.EXPECT().UpdateMessage(mock.Anything, 8, mock.Anything).Return(models.Message{}, fmt.Errorf("%w: message 8", repository.ErrMessageChanged))
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodPost, "/api/messages/4/cancel", http.StatusNotFound},
+		{http.MethodPost, "/api/messages/5/cancel", http.StatusConflict},
+		{http.MethodPost, "/api/messages/abc/cancel", http.StatusBadRequest},
+		{http.MethodDelete, "/api/messages/5", http.StatusConflict},
+		{http.MethodDelete, "/api/messages/6", http.StatusInternalServerError},
+		{http.MethodDelete, "/api/messages/0", http.StatusBadRequest},
+		{http.MethodPatch, "/api/messages/5", http.StatusConflict},
+		{http.MethodPatch, "/api/messages/7", http.StatusBadRequest},
+		{http.MethodPatch, "/api/messages/8", http.StatusConflict},
+	} {
+		resp, err := suite.app.Test(httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{"content":"changed"}`)))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), tc.status, resp.StatusCode, tc.method+" "+tc.path)
+
+		var result map[string]interface{}
+		body, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(body, &result)
+		assert.False(suite.T(), result["success"].(bool))
+		assert.NotEmpty(suite.T(), result["error"])
+	}
+}
+
+// TestCancelAndDeleteMessage, iptal ve silme endpointlerini test eder
+func (suite *MessageControllerTestSuite) TestCancelAndDeleteMessage() {
+	cancelledAt := time.Now()
+	suite.mockService.EXPECT().CancelMessage(mock.Anything, 3).Return(models.Message{ID: 3, CancelledAt: &cancelledAt}, nil)
+	suite.mockService.EXPECT().DeleteMessage(mock.Anything, 3).Return(nil)
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages/3/cancel", nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var result models.MessageDetailResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.True(suite.T(), result.Success)
+	assert.Equal(suite.T(), models.MessageStatusCancelled, result.Message.Status())
+
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodDelete, "/api/messages/3", nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+}
+
+// TestResendMessage, yeniden gönderim endpointini test eder
+func (suite *MessageControllerTestSuite) TestResendMessage() {
+	parentID := 1
+	resend := models.Message{ID: 3, PhoneNumber: "+90123456789", Content: "Test message 1", IsSent: true, ParentID: &parentID}
+	suite.mockService.EXPECT().ResendMessage(mock.Anything, 1).Return(resend, nil)
+	suite.mockService.EXPECT().ResendMessage(mock.Anything, 2).Return(models.Message{}, fmt.Errorf("%w: message 2 is unsent", services.ErrMessageNotResendable))
+	suite.mockService.EXPECT().ResendMessage(mock.Anything, 404).Return(models.Message{}, repository.ErrMessageNotFound)
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages/1/resend", nil))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var result models.MessageDetailResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.True(suite.T(), result.Success)
+	assert.Equal(suite.T(), resend.ID, result.Message.ID)
+	assert.Equal(suite.T(), &parentID, result.Message.ParentID)
+
+	for path, status := range map[string]int{
+		"/api/messages/2/resend":   http.StatusConflict,
+		"/api/messages/404/resend": http.StatusNotFound,
+		"/api/messages/abc/resend": http.StatusBadRequest,
+	} {
+		resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, path, nil))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), status, resp.StatusCode, path)
+	}
+}
+
+// TestCreateMessage, mesaj oluşturma endpointini test eder
+func (suite *MessageControllerTestSuite) TestCreateMessage() {
+	created := models.Message{ID: 9, PhoneNumber: "+905321234567", CountryCode: "TR", Content: "code 1234", Priority: models.MessagePriorityHigh}
+	suite.mockService.EXPECT().CreateMessage(mock.Anything, models.Message{
+		PhoneNumber: "0532 123 45 67",
+		Content:     "code 1234",
+		Priority:    models.MessagePriorityHigh,
+	}).Return(created, nil).Once()
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages",
+		strings.NewReader(`{"content":"code 1234","phoneNumber":"0532 123 45 67","priority":1}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusCreated, resp.StatusCode)
+
+	var result models.MessageDetailResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.True(suite.T(), result.Success)
+	assert.Equal(suite.T(), "+905321234567", result.Message.PhoneNumber)
+	assert.Equal(suite.T(), "TR", result.Message.CountryCode)
+
+	// Geçersiz numaralar 400 ile reddedilir
+	suite.mockService.EXPECT().CreateMessage(mock.Anything, mock.Anything).
+		Return(models.Message{}, fmt.Errorf("%w: invalid phone number", services.ErrInvalidMessage)).Once()
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages",
+		strings.NewReader(`{"content":"hello","phoneNumber":"123"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages", strings.NewReader(`not json`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestPreviewMessage, içerik önizleme endpointini test eder
+func (suite *MessageControllerTestSuite) TestPreviewMessage() {
+	preview := models.ContentPreview{Encoding: "UCS-2", Characters: 8, Units: 8, Segments: 1, UnitsPerSegment: 70, Remaining: 62, NonGSMCharacters: []string{"ş"}, MaxCharacters: 1000, Valid: true}
+	suite.mockService.EXPECT().PreviewContent("Teşekkür", "").Return(preview).Once()
+
+	resp, err := suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages/preview", strings.NewReader(`{"content":"Teşekkür"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+
+	var result models.MessagePreviewResponse
+	body, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(body, &result)
+	assert.True(suite.T(), result.Success)
+	assert.Equal(suite.T(), preview, result.Preview)
+
+	// Harf çevirisi dili servise iletilir
+	transliterated := models.ContentPreview{Transliterated: "Tesekkür", Encoding: "GSM-7", Characters: 8, Units: 8, Segments: 1, UnitsPerSegment: 160, Remaining: 152, MaxCharacters: 1000, Valid: true}
+	suite.mockService.EXPECT().PreviewContent("Teşekkür", "tr").Return(transliterated).Once()
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages/preview", strings.NewReader(`{"content":"Teşekkür","transliteration":"tr"}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
+	var transliteratedResult models.MessagePreviewResponse
+	body, _ = io.ReadAll(resp.Body)
+	json.Unmarshal(body, &transliteratedResult)
+	assert.Equal(suite.T(), transliterated, transliteratedResult.Preview)
+
+	resp, err = suite.app.Test(httptest.NewRequest(http.MethodPost, "/api/messages/preview", strings.NewReader(`{"content":5}`)))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), http.StatusBadRequest, resp.StatusCode)
 }
 
 // TestMessageControllerSuite çalıştırma fonksiyonu
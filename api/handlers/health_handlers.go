@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/alper.meric/messaging-system/health"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthController handles liveness and readiness probes
+type HealthController struct {
+	checker *health.Checker
+}
+
+// NewHealthController creates a new health controller
+func NewHealthController(checker *health.Checker) *HealthController {
+	return &HealthController{
+		checker: checker,
+	}
+}
+
+// Liveness reports that the process is alive and serving requests
+// @Summary Liveness probe
+// @Description Returns 200 as long as the process can serve HTTP requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /healthz [get]
+func (hc *HealthController) Liveness(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": health.StatusOK,
+	})
+}
+
+// Readiness checks the dependencies needed to serve traffic
+// @Summary Readiness probe
+// @Description Pings PostgreSQL, Redis and optionally the webhook host, returning per-dependency status and latency
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (hc *HealthController) Readiness(c *fiber.Ctx) error {
+	report := hc.checker.Run(c.UserContext())
+
+	status := fiber.StatusOK
+	if !report.Ready() {
+		status = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(status).JSON(report)
+}
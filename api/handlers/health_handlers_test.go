@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alper.meric/messaging-system/health"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHealthApp(checks ...health.Check) *fiber.App {
+	controller := NewHealthController(health.NewChecker(time.Second, checks...))
+
+	app := fiber.New()
+	app.Get("/healthz", controller.Liveness)
+	app.Get("/readyz", controller.Readiness)
+	return app
+}
+
+func TestLiveness(t *testing.T) {
+	app := newHealthApp(health.Check{Name: "postgres", Required: true, Probe: func(context.Context) error {
+		return errors.New("down")
+	}})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "liveness must not depend on dependencies")
+}
+
+func TestReadiness(t *testing.T) {
+	redisErr := errors.New("connection refused")
+	postgresUp := true
+
+	app := newHealthApp(
+		health.Check{Name: "postgres", Required: true, Probe: func(context.Context) error {
+			if postgresUp {
+				return nil
+			}
+			return errors.New("connection refused")
+		}},
+		health.Check{Name: "redis", Required: false, Probe: func(context.Context) error { return redisErr }},
+	)
+
+	// Optional dependency down: still ready
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report health.Report
+	body, _ := io.ReadAll(resp.Body)
+	assert.NoError(t, json.Unmarshal(body, &report))
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["postgres"].Status)
+	assert.Equal(t, health.StatusDown, report.Checks["redis"].Status)
+
+	// Required dependency down: 503
+	postgresUp = false
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	body, _ = io.ReadAll(resp.Body)
+	assert.NoError(t, json.Unmarshal(body, &report))
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/alper.meric/messaging-system/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// unmatchedRoute labels requests that did not match any registered route,
+// keeping metric cardinality bounded
+const unmatchedRoute = "unmatched"
+
+// unmatchedLocal is the Fiber local set by NotFoundHandler
+const unmatchedLocal = "unmatched"
+
+// RequestLogger attaches a logger carrying the request ID (and trace ID, when
+// tracing) to the request's user context and writes one access log line per
+// request. It must run after the requestid and tracing middleware.
+func RequestLogger() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := fmt.Sprint(c.Locals(requestid.ConfigDefault.ContextKey))
+		logger := logging.FromContext(c.UserContext()).With(logging.KeyRequestID, requestID)
+		if spanContext := trace.SpanContextFromContext(c.UserContext()); spanContext.HasTraceID() {
+			logger = logger.With(logging.KeyTraceID, spanContext.TraceID().String())
+		}
+		c.SetUserContext(logging.WithLogger(c.UserContext(), logger))
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		logger.Info("request completed",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", c.IP(),
+		)
+		return err
+	}
+}
+
+// RequestTimeout bounds the work done on behalf of a request by putting a
+// deadline on its user context. A zero timeout disables it.
+func RequestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if timeout <= 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		return c.Next()
+	}
+}
+
+// TracingMiddleware starts a server span for every request, continuing any
+// W3C trace context sent by the caller, and stores it in the user context
+func TracingMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), requestHeaderCarrier{c})
+		ctx, span := tracing.Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+			),
+		)
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := routeLabel(c)
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+
+		return err
+	}
+}
+
+// requestHeaderCarrier adapts Fiber request headers to a propagation.TextMapCarrier
+type requestHeaderCarrier struct {
+	c *fiber.Ctx
+}
+
+func (r requestHeaderCarrier) Get(key string) string {
+	return r.c.Get(key)
+}
+
+func (r requestHeaderCarrier) Set(key, value string) {
+	r.c.Request().Header.Set(key, value)
+}
+
+func (r requestHeaderCarrier) Keys() []string {
+	keys := make([]string, 0)
+	r.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// MetricsMiddleware records the count and latency of every HTTP request
+func MetricsMiddleware(m metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+
+		m.ObserveHTTPRequest(c.Method(), routeLabel(c), status, time.Since(start))
+		return err
+	}
+}
+
+// responseStatus returns the status code a request will be answered with.
+// Errors are turned into responses by the error handler later on, so the
+// status code is derived from the error itself when there is one.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	if e, ok := err.(*fiber.Error); ok {
+		return e.Code
+	}
+	return fiber.StatusInternalServerError
+}
+
+// routeLabel returns the matched route pattern of a finished request
+func routeLabel(c *fiber.Ctx) string {
+	if c.Locals(unmatchedLocal) != nil {
+		return unmatchedRoute
+	}
+	return c.Route().Path
+}
+
+// NotFoundHandler responds to requests that did not match any route
+func NotFoundHandler(c *fiber.Ctx) error {
+	logging.FromContext(c.UserContext()).Warn("route not found", "method", c.Method(), "path", c.Path())
+	c.Locals(unmatchedLocal, true)
+	return c.Status(fiber.StatusNotFound).SendString("Page not found")
+}
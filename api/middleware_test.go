@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alper.meric/messaging-system/config"
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/alper.meric/messaging-system/tracing"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	recorder := metrics.NewMemoryMetrics()
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(MetricsMiddleware(recorder))
+	app.Get("/api/messages/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "0" {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid id")
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Use(NotFoundHandler)
+
+	for _, path := range []string{"/api/messages/1", "/api/messages/2", "/api/messages/0", "/unknown"} {
+		_, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.NoError(t, err)
+	}
+
+	// Requests are labelled by route pattern, not by raw path
+	assert.Equal(t, 2, recorder.Count(metrics.HTTPRequests, "GET", "/api/messages/:id", "200"))
+	assert.Equal(t, 1, recorder.Count(metrics.HTTPRequests, "GET", "/api/messages/:id", "400"))
+	assert.Equal(t, 1, recorder.Count(metrics.HTTPRequests, "GET", unmatchedRoute, "404"))
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(previous)
+
+	app := fiber.New()
+	app.Use(requestid.New())
+	app.Use(RequestLogger())
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		logging.FromContext(c.UserContext()).Info("handler called")
+		return c.SendString("pong")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-123")
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "req-123", resp.Header.Get(fiber.HeaderXRequestID))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, "req-123", entry[logging.KeyRequestID])
+	}
+
+	// A request ID is generated when the client does not send one
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderXRequestID))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestTimeout(time.Minute))
+	app.Get("/deadline", func(c *fiber.Ctx) error {
+		deadline, ok := c.UserContext().Deadline()
+		assert.True(t, ok, "handler context must carry a deadline")
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/deadline", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// A zero timeout leaves the context alone
+	app = fiber.New()
+	app.Use(RequestTimeout(0))
+	app.Get("/deadline", func(c *fiber.Ctx) error {
+		_, ok := c.UserContext().Deadline()
+		assert.False(t, ok)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	_, err = app.Test(httptest.NewRequest(http.MethodGet, "/deadline", nil))
+	assert.NoError(t, err)
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	provider, exporter := tracing.NewInMemoryProvider()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+	_, err := tracing.Setup(context.Background(), config.TracingConfig{})
+	assert.NoError(t, err)
+
+	app := fiber.New()
+	app.Use(TracingMiddleware())
+	app.Get("/api/messages/:id", func(c *fiber.Ctx) error {
+		_, span := tracing.Start(c.UserContext(), "handler")
+		span.End()
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/messages/7", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	_, err = app.Test(req)
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 2)
+
+	handlerSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "GET /api/messages/:id", serverSpan.Name)
+	assert.Equal(t, traceID, serverSpan.SpanContext.TraceID().String(), "incoming trace context must be continued")
+	assert.Equal(t, serverSpan.SpanContext.SpanID(), handlerSpan.Parent.SpanID())
+}
@@ -1,25 +1,47 @@
 package api
 
 import (
-	"log"
+	"time"
 
 	"github.com/alper.meric/messaging-system/api/handlers"
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(app *fiber.App, controller *handlers.MessageController) {
+func SetupRoutes(app *fiber.App, controller *handlers.MessageController, healthController *handlers.HealthController, m metrics.Metrics, requestTimeout time.Duration) {
 	// Add middleware
-	app.Use(logger.New())
+	app.Use(RequestTimeout(requestTimeout))
+	app.Use(requestid.New())
+	app.Use(TracingMiddleware())
+	app.Use(RequestLogger())
 	app.Use(cors.New())
+	app.Use(MetricsMiddleware(m))
 
 	// API Endpoints - Using controller methods
 	api := app.Group("/api")
 	api.Post("/service", controller.ServiceControl)
 	api.Get("/service/status", controller.ServiceStatus)
-	api.Get("/messages", controller.GetSentMessages)
+	api.Get("/messages", controller.ListMessages)
+	api.Post("/messages", controller.CreateMessage)
+	api.Post("/messages/preview", controller.PreviewMessage)
+	api.Get("/messages/by-external-id/:externalId", controller.GetMessageByExternalID)
+	api.Get("/messages/:id", controller.GetMessage)
+	api.Patch("/messages/:id", controller.UpdateMessage)
+	api.Delete("/messages/:id", controller.DeleteMessage)
+	api.Post("/messages/:id/cancel", controller.CancelMessage)
+	api.Post("/messages/:id/resend", controller.ResendMessage)
+
+	// Health probes
+	app.Get("/healthz", healthController.Liveness)
+	app.Get("/readyz", healthController.Readiness)
+
+	// Prometheus metrics
+	app.Get("/metrics", adaptor.HTTPHandler(m.Handler()))
 
 	// Swagger UI - serve static files
 	app.Static("/swagger", "./docs/swagger-ui")
@@ -35,10 +57,7 @@ func SetupRoutes(app *fiber.App, controller *handlers.MessageController) {
 	})
 
 	// 404 Handler
-	app.Use(func(c *fiber.Ctx) error {
-		log.Printf("404 Not Found: %s %s", c.Method(), c.Path())
-		return c.Status(fiber.StatusNotFound).SendString("Page not found")
-	})
+	app.Use(NotFoundHandler)
 }
 
 // ErrorHandler handles errors returned from routes
@@ -52,7 +71,7 @@ func ErrorHandler(c *fiber.Ctx, err error) error {
 	}
 
 	// Log error
-	log.Printf("Error: %v", err)
+	logging.FromContext(c.UserContext()).Error("request failed", "status", code, logging.KeyError, err)
 
 	// Return JSON error response
 	return c.Status(code).JSON(fiber.Map{
@@ -2,32 +2,158 @@ package clients
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ProviderWebhook, webhook sağlayıcısının metrik ve log etiketidir
+const ProviderWebhook = "webhook"
+
+// Hata sınıfları, metriklerde error_class etiketi olarak kullanılır
+const (
+	ErrorClassTimeout          = "timeout"
+	ErrorClassConnection       = "connection"
+	ErrorClassClientStatus     = "http_4xx"
+	ErrorClassServerStatus     = "http_5xx"
+	ErrorClassUnexpectedStatus = "unexpected_status" // kabul edilmeyen 1xx, 2xx veya 3xx durum kodları
+	ErrorClassInvalidResponse  = "invalid_response"
+	ErrorClassCanceled         = "canceled"
+	ErrorClassOther            = "other"
+)
+
+// defaultTimeout, WithTimeout verilmediğinde kullanılan webhook süre sınırıdır
+const defaultTimeout = 10 * time.Second
+
+// ErrInvalidResponse, dış servisin yanıtı ayrıştırılamadığında döner
+var ErrInvalidResponse = errors.New("invalid response from external service")
+
+// StatusError, dış servis beklenmeyen bir HTTP durum kodu döndürdüğünde oluşur
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("external service returned error status: %d", e.StatusCode)
+}
+
 // MessageClient, mesaj gönderimi için HTTP istemcisini temsil eder
 type MessageClient struct {
 	webhookURL string
 	client     *http.Client
 	dryRun     bool
+	metrics    metrics.Metrics
+}
+
+// Option, MessageClient için isteğe bağlı bir ayardır
+type Option func(*MessageClient)
+
+// WithMetrics, gönderim gecikmelerinin kaydedileceği metrik kaydedicisini ayarlar
+func WithMetrics(m metrics.Metrics) Option {
+	return func(c *MessageClient) {
+		c.metrics = m
+	}
+}
+
+// WithTimeout, tek bir webhook çağrısı için süre sınırını ayarlar
+func WithTimeout(d time.Duration) Option {
+	return func(c *MessageClient) {
+		c.client.Timeout = d
+	}
 }
 
 // NewMessageClient, yeni bir MessageClient oluşturur
-func NewMessageClient(webhookURL string, dryRun bool) *MessageClient {
-	return &MessageClient{
+func NewMessageClient(webhookURL string, dryRun bool, opts ...Option) *MessageClient {
+	c := &MessageClient{
 		webhookURL: webhookURL,
 		client: &http.Client{
-			Timeout: 10 * time.Second, // 10 saniyelik timeout
+			Timeout: defaultTimeout,
 		},
-		dryRun: dryRun,
+		dryRun:  dryRun,
+		metrics: metrics.NewNoop(),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Provider, mesajların gönderildiği sağlayıcının adını döndürür
+func (c *MessageClient) Provider() string {
+	return ProviderWebhook
+}
+
+// Ping, webhook sunucusuna TCP bağlantısı kurulabildiğini kontrol eder
+func (c *MessageClient) Ping(ctx context.Context) error {
+	target, err := url.Parse(c.webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	port := target.Port()
+	if port == "" {
+		port = "80"
+		if target.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("webhook host unreachable: %w", err)
+	}
+	return conn.Close()
+}
+
+// ErrorClass, bir gönderim hatasını metrik etiketi olarak kullanılacak sınıfa ayırır
+func ErrorClass(err error) string {
+	var statusErr *StatusError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode >= 500:
+			return ErrorClassServerStatus
+		case statusErr.StatusCode >= 400:
+			return ErrorClassClientStatus
+		default:
+			return ErrorClassUnexpectedStatus
+		}
+	case errors.Is(err, ErrInvalidResponse):
+		return ErrorClassInvalidResponse
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &netErr):
+		return ErrorClassConnection
+	default:
+		return ErrorClassOther
+	}
+}
+
+// IsRejection, hatanın dış servisin mesajı kalıcı olarak reddettiğini (4xx) belirtip belirtmediğini döndürür
+func IsRejection(err error) bool {
+	return ErrorClass(err) == ErrorClassClientStatus
 }
 
 // MessageResponse, mesaj gönderimi yanıtını temsil eder
@@ -37,11 +163,27 @@ type MessageResponse struct {
 }
 
 // SendMessage, belirtilen mesajı dış servise gönderir ve mesaj ID'sini döndürür
-func (c *MessageClient) SendMessage(msg models.Message) (string, error) {
+func (c *MessageClient) SendMessage(ctx context.Context, msg models.Message) (externalID string, err error) {
+	ctx, span := tracing.Start(ctx, "MessageClient.SendMessage",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("message.id", msg.ID),
+			attribute.String("message.provider", c.Provider()),
+			attribute.Bool("message.dry_run", c.dryRun),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	logger := logging.FromContext(ctx).With(
+		logging.KeyMessageID, msg.ID,
+		logging.KeyProvider, c.Provider(),
+	)
+
 	// Eğer dry run modunda ise, mesajları gerçekten göndermez
 	if c.dryRun {
-		log.Printf("DRY RUN: Would send message to %s: %s", msg.PhoneNumber, msg.Content)
-		return fmt.Sprintf("dry-run-id-%d", msg.ID), nil
+		externalID = fmt.Sprintf("dry-run-id-%d", msg.ID)
+		logger.Info("dry run, message not sent", logging.KeyExternalID, externalID, "content_length", len(msg.Content))
+		return externalID, nil
 	}
 
 	// İstek verilerini hazırla
@@ -56,34 +198,48 @@ func (c *MessageClient) SendMessage(msg models.Message) (string, error) {
 	}
 
 	// HTTP POST isteği gönder
-	req, err := http.NewRequest(http.MethodPost, c.webhookURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
+	// W3C trace-context başlıklarını webhook'a ilet
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	logger.Debug("sending message to webhook")
+
+	start := time.Now()
 	resp, err := c.client.Do(req)
+	c.metrics.ObserveSendLatency(c.Provider(), time.Since(start))
 	if err != nil {
 		return "", fmt.Errorf("failed to send HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	// Yanıt durumunu kontrol et
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
-		return "", fmt.Errorf("external service returned error status: %d", resp.StatusCode)
+		return "", &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Dış mesaj ID'sini almak için yanıtı ayrıştır
 	var responseData MessageResponse
 	err = json.NewDecoder(resp.Body).Decode(&responseData)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w: %w", ErrInvalidResponse, err)
 	}
 
 	// Dış mesaj ID'sini çıkar
 	if responseData.MessageID == "" {
-		return "", errors.New("external service did not return a valid message ID")
+		return "", fmt.Errorf("external service did not return a valid message ID: %w", ErrInvalidResponse)
 	}
 
+	logger.Debug("message accepted by webhook",
+		logging.KeyExternalID, responseData.MessageID,
+		"status", resp.StatusCode,
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+
 	return responseData.MessageID, nil
 }
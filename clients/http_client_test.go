@@ -1,13 +1,20 @@
 package clients
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/alper.meric/messaging-system/config"
+	"github.com/alper.meric/messaging-system/metrics"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/tracing"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
 )
 
 func TestMessageClientSendMessage(t *testing.T) {
@@ -47,14 +54,16 @@ func TestMessageClientSendMessage(t *testing.T) {
 		defer server.Close()
 
 		// Create client with mock server URL
-		client := NewMessageClient(server.URL, false)
+		recorder := metrics.NewMemoryMetrics()
+		client := NewMessageClient(server.URL, false, WithMetrics(recorder))
 
 		// Test send message
-		externalID, err := client.SendMessage(msg)
+		externalID, err := client.SendMessage(context.Background(), msg)
 
 		// Assertions
 		assert.NoError(t, err)
 		assert.Equal(t, "test-id-123", externalID)
+		assert.Equal(t, 1, recorder.Count(metrics.SendLatency, ProviderWebhook))
 	})
 
 	t.Run("server error", func(t *testing.T) {
@@ -68,11 +77,61 @@ func TestMessageClientSendMessage(t *testing.T) {
 		client := NewMessageClient(server.URL, false)
 
 		// Test send message
-		_, err := client.SendMessage(msg)
+		_, err := client.SendMessage(context.Background(), msg)
 
 		// Assertions
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "returned error status: 500")
+		assert.Equal(t, ErrorClassServerStatus, ErrorClass(err))
+		assert.False(t, IsRejection(err))
+	})
+
+	t.Run("unexpected status", func(t *testing.T) {
+		// Mock server setup that answers with a status the client doesn't accept
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := NewMessageClient(server.URL, false)
+
+		_, err := client.SendMessage(context.Background(), msg)
+
+		assert.Error(t, err)
+		assert.Equal(t, ErrorClassUnexpectedStatus, ErrorClass(err))
+		assert.False(t, IsRejection(err))
+	})
+
+	t.Run("rejected by provider", func(t *testing.T) {
+		// Mock server setup that rejects the message
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		// Create client with mock server URL
+		client := NewMessageClient(server.URL, false)
+
+		// Test send message
+		_, err := client.SendMessage(context.Background(), msg)
+
+		// Assertions
+		assert.Error(t, err)
+		assert.Equal(t, ErrorClassClientStatus, ErrorClass(err))
+		assert.True(t, IsRejection(err))
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		// Mock server that is closed before the request is made
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		client := NewMessageClient(server.URL, false)
+
+		_, err := client.SendMessage(context.Background(), msg)
+
+		assert.Error(t, err)
+		assert.Equal(t, ErrorClassConnection, ErrorClass(err))
 	})
 
 	t.Run("invalid response", func(t *testing.T) {
@@ -88,11 +147,12 @@ func TestMessageClientSendMessage(t *testing.T) {
 		client := NewMessageClient(server.URL, false)
 
 		// Test send message
-		_, err := client.SendMessage(msg)
+		_, err := client.SendMessage(context.Background(), msg)
 
 		// Assertions
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to decode response")
+		assert.Equal(t, ErrorClassInvalidResponse, ErrorClass(err))
 	})
 
 	t.Run("missing message ID", func(t *testing.T) {
@@ -112,22 +172,99 @@ func TestMessageClientSendMessage(t *testing.T) {
 		client := NewMessageClient(server.URL, false)
 
 		// Test send message
-		_, err := client.SendMessage(msg)
+		_, err := client.SendMessage(context.Background(), msg)
 
 		// Assertions
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "did not return a valid message ID")
 	})
 
+	t.Run("trace context propagation", func(t *testing.T) {
+		provider, exporter := tracing.NewInMemoryProvider()
+		previous := otel.GetTracerProvider()
+		otel.SetTracerProvider(provider)
+		defer otel.SetTracerProvider(previous)
+		_, err := tracing.Setup(context.Background(), config.TracingConfig{})
+		assert.NoError(t, err)
+
+		var traceparent string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+			json.NewEncoder(w).Encode(map[string]string{"messageId": "test-id-123"})
+		}))
+		defer server.Close()
+
+		client := NewMessageClient(server.URL, false)
+		_, err = client.SendMessage(context.Background(), msg)
+		assert.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "MessageClient.SendMessage", spans[0].Name)
+		assert.Contains(t, traceparent, spans[0].SpanContext.TraceID().String(), "webhook must receive the client span's trace ID")
+		assert.Contains(t, traceparent, spans[0].SpanContext.SpanID().String())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := NewMessageClient(server.URL, false, WithTimeout(20*time.Millisecond))
+
+		_, err := client.SendMessage(context.Background(), msg)
+
+		assert.Error(t, err)
+		assert.Equal(t, ErrorClassTimeout, ErrorClass(err))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		received := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Bağlantının kapandığını fark etmek için gövde okunmalı
+			_, _ = io.Copy(io.Discard, r.Body)
+			close(received)
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		client := NewMessageClient(server.URL, false)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-received
+			cancel()
+		}()
+
+		_, err := client.SendMessage(ctx, msg)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, ErrorClassCanceled, ErrorClass(err))
+	})
+
 	t.Run("dry run mode", func(t *testing.T) {
 		// Create client in dry run mode
 		client := NewMessageClient("http://example.com", true)
 
 		// Test send message in dry run mode
-		externalID, err := client.SendMessage(msg)
+		externalID, err := client.SendMessage(context.Background(), msg)
 
 		// Assertions
 		assert.NoError(t, err)
 		assert.Contains(t, externalID, "dry-run-id-1")
 	})
 }
+
+func TestMessageClientPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	client := NewMessageClient(server.URL+"/webhook", false)
+	assert.NoError(t, client.Ping(context.Background()))
+
+	server.Close()
+	err := client.Ping(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook host unreachable")
+}
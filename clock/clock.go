@@ -0,0 +1,45 @@
+package clock
+
+import "time"
+
+// Clock tells the time and creates timers, so code waiting for a point in
+// time can be tested without sleeping
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single event on a Clock
+type Timer interface {
+	// C delivers the time the timer fired at
+	C() <-chan time.Time
+	// Stop prevents the timer from firing and reports whether it was still pending
+	Stop() bool
+}
+
+// New returns the wall clock
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
@@ -0,0 +1,120 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock that only moves when told to, for tests
+type Fake struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFake creates a fake clock standing at now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mutex)
+	return f
+}
+
+// Now returns the time the clock stands at
+func (f *Fake) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// NewTimer creates a timer firing once the clock has been advanced by d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t := &fakeTimer{clock: f, deadline: f.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- f.now
+		return t
+	}
+	f.timers = append(f.timers, t)
+	f.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forward by d, firing the timers that become due
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	now := f.now.Add(d)
+	f.mutex.Unlock()
+	f.Set(now)
+}
+
+// Set moves the clock to t, firing the timers that become due
+func (f *Fake) Set(t time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = t
+	sort.Slice(f.timers, func(i, j int) bool { return f.timers[i].deadline.Before(f.timers[j].deadline) })
+
+	pending := f.timers[:0]
+	for _, timer := range f.timers {
+		if timer.deadline.After(t) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- timer.deadline
+	}
+	f.timers = pending
+	f.cond.Broadcast()
+}
+
+// WaitForTimers blocks until at least n timers are pending, i.e. the code
+// under test has reached the point where it waits for the clock
+func (f *Fake) WaitForTimers(n int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for len(f.timers) < n {
+		f.cond.Wait()
+	}
+}
+
+// Timers returns the deadlines of the pending timers, earliest first
+func (f *Fake) Timers() []time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	deadlines := make([]time.Time, len(f.timers))
+	for i, timer := range f.timers {
+		deadlines[i] = timer.deadline
+	}
+	sort.Slice(deadlines, func(i, j int) bool { return deadlines[i].Before(deadlines[j]) })
+	return deadlines
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for i, timer := range f.timers {
+		if timer == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTimers(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+
+	first := clock.NewTimer(time.Minute)
+	second := clock.NewTimer(2 * time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	assert.Equal(t, []time.Time{start.Add(time.Minute), start.Add(2 * time.Minute)}, clock.Timers())
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), clock.Now())
+	select {
+	case fired := <-first.C():
+		assert.Equal(t, start.Add(time.Minute), fired)
+	default:
+		t.Fatal("due timer did not fire")
+	}
+	select {
+	case <-second.C():
+		t.Fatal("timer fired early")
+	case <-stopped.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	assert.False(t, first.Stop(), "a fired timer is no longer pending")
+
+	clock.Advance(time.Minute)
+	<-second.C()
+	assert.Empty(t, clock.Timers())
+
+	// A timer without a duration fires right away
+	<-clock.NewTimer(0).C()
+}
+
+func TestFakeWaitForTimers(t *testing.T) {
+	clock := NewFake(time.Now())
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		clock.NewTimer(time.Second)
+	}()
+
+	clock.WaitForTimers(1)
+	assert.Len(t, clock.Timers(), 1)
+}
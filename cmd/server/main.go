@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,8 +13,16 @@ import (
 	"github.com/alper.meric/messaging-system/api/handlers"
 	"github.com/alper.meric/messaging-system/clients"
 	"github.com/alper.meric/messaging-system/config"
+	"github.com/alper.meric/messaging-system/health"
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/alper.meric/messaging-system/phone"
+	"github.com/alper.meric/messaging-system/recipient"
 	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/schedule"
 	"github.com/alper.meric/messaging-system/services"
+	"github.com/alper.meric/messaging-system/sms"
+	"github.com/alper.meric/messaging-system/tracing"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -33,59 +41,91 @@ import (
 // @BasePath /api
 // @schemes http
 func main() {
-	log.Println("Starting messaging system...")
-
 	// Load configuration
-	cfg := config.LoadConfig()
-
-	// Set up PostgreSQL repository
-	postgresRepo, err := repository.NewPostgresRepository(
-		cfg.DB.Host,
-		cfg.DB.Port,
-		cfg.DB.User,
-		cfg.DB.Password,
-		cfg.DB.Name,
-	)
+	cfg, err := config.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to create PostgreSQL repository: %v", err)
+		fatal("invalid configuration", err)
 	}
-	log.Println("PostgreSQL repository created successfully")
 
-	// Set up Redis repository
-	var redisRepo *repository.RedisRepository
-	redisRepo, err = repository.NewRedisRepository(
-		cfg.Redis.Addr,
-		cfg.Redis.Password,
-		cfg.Redis.DB,
-	)
+	// Set up structured logging
+	logging.Setup(cfg.Log)
+
+	// Schema migrations run as a separate subcommand
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			fatal("migration failed", err)
+		}
+		return
+	}
+	slog.Info("starting messaging system", "config", cfg)
+
+	// Set up tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Printf("Warning: Failed to create Redis repository (caching will be disabled): %v", err)
-		redisRepo = nil
-	} else {
-		log.Println("Redis repository created successfully")
+		fatal("failed to set up tracing", err)
+	}
+
+	// Set up metrics
+	appMetrics := metrics.NewNoop()
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.NewPrometheusMetrics()
 	}
 
+	// Set up the message repository
+	messageRepo, sqlRepo, err := newMessageRepository(cfg)
+	if err != nil {
+		fatal("failed to create message repository", err)
+	}
+	slog.Info("message repository created successfully", "driver", cfg.DB.Driver)
+
+	// Set up the cache
+	cacheRepo, redisRepo, err := newCacheRepository(cfg, appMetrics)
+	if err != nil {
+		fatal("failed to create cache repository", err)
+	}
+	slog.Info("cache repository created successfully", "driver", cfg.Cache.Driver)
+
 	// HTTP client oluşturma
 	messageClient := clients.NewMessageClient(
 		cfg.App.WebhookURL,
 		cfg.App.MessageSendDryRun,
+		clients.WithMetrics(appMetrics),
+		clients.WithTimeout(seconds(cfg.App.WebhookTimeoutSeconds)),
 	)
-	log.Println("HTTP client created successfully")
+	slog.Info("HTTP client created successfully", logging.KeyProvider, messageClient.Provider())
+
+	if region := cfg.App.DefaultPhoneRegion; region != "" && !phone.SupportedRegion(region) {
+		fatal("invalid default phone region", fmt.Errorf("%w: %s", phone.ErrUnknownRegion, region))
+	}
+	if language := cfg.App.Transliteration; language != "" && !sms.SupportedLanguage(language) {
+		fatal("invalid transliteration", fmt.Errorf("unsupported language: %s", language))
+	}
+
+	// Sending schedule and windows
+	scheduleOpts, err := newScheduleOptions(cfg.App)
+	if err != nil {
+		fatal("invalid sending schedule", err)
+	}
 
 	// Prepare message sending service with repositories
-	messageService := services.NewMessageService(cfg, postgresRepo, redisRepo, messageClient)
+	messageService := services.NewMessageService(cfg, messageRepo, cacheRepo, messageClient,
+		append(scheduleOpts, services.WithMetrics(appMetrics))...)
 
 	// HTTP sunucusu ve API oluşturma
 	app := fiber.New(fiber.Config{
-		AppName:      "Messaging System",
-		ErrorHandler: api.ErrorHandler,
+		AppName:               "Messaging System",
+		ErrorHandler:          api.ErrorHandler,
+		DisableStartupMessage: true,
 	})
 
 	// Controller sadece service'e bağımlı olmalı, repository'ye değil
 	messageController := handlers.NewMessageController(messageService)
 
+	// Readiness checks
+	healthController := handlers.NewHealthController(newHealthChecker(cfg.Health, cfg.DB.Driver, sqlRepo, redisRepo, messageClient))
+
 	// API endpoint'leri
-	api.SetupRoutes(app, messageController)
+	api.SetupRoutes(app, messageController, healthController, appMetrics, seconds(cfg.Server.RequestTimeoutSeconds))
 
 	// Create a channel for graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -94,23 +134,180 @@ func main() {
 	// Start server in a goroutine
 	go func() {
 		addr := fmt.Sprintf(":%d", cfg.Server.Port)
-		log.Printf("Server listening on %s", addr)
+		slog.Info("server listening", "addr", addr)
 		if err := app.Listen(addr); err != nil {
-			log.Fatalf("Server error: %v", err)
+			fatal("server error", err)
 		}
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("shutting down server")
 
-	// Give 5 seconds to shutdown gracefully
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), seconds(cfg.Server.ShutdownTimeoutSeconds))
 	defer cancel()
 
+	// Stop accepting requests first so no new work reaches the service
 	if err := app.ShutdownWithContext(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		slog.Error("server forced to shutdown", logging.KeyError, err)
+	}
+
+	// Let the current batch finish so no message is left between send and mark-as-sent
+	if err := messageService.Shutdown(ctx); err != nil {
+		slog.Error("message service forced to shutdown", logging.KeyError, err)
+	}
+
+	if redisRepo != nil {
+		if err := redisRepo.Close(); err != nil {
+			slog.Warn("failed to close Redis client", logging.KeyError, err)
+		}
+	}
+
+	if sqlRepo != nil {
+		if err := sqlRepo.Close(); err != nil {
+			slog.Warn("failed to close database connection pool", logging.KeyError, err)
+		}
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("failed to flush traces", logging.KeyError, err)
+	}
+
+	slog.Info("server gracefully stopped")
+}
+
+// newMessageRepository creates the message store selected by db.driver. SQL
+// backends are also returned as a GormRepository for health checks and
+// shutdown, which is nil for the in-memory store.
+func newMessageRepository(cfg *config.Configuration) (repository.MessageRepository, *repository.GormRepository, error) {
+	opts := []repository.GormOption{
+		repository.WithQueryTimeout(seconds(cfg.DB.QueryTimeoutSeconds)),
+		repository.WithAutoMigrate(cfg.DB.AutoMigrate),
 	}
 
-	log.Println("Server gracefully stopped")
+	switch cfg.DB.Driver {
+	case config.DBDriverPostgres:
+		postgresRepo, err := repository.NewPostgresRepository(
+			cfg.DB.Host,
+			cfg.DB.Port,
+			cfg.DB.User,
+			cfg.DB.Password.Value(),
+			cfg.DB.Name,
+			opts...,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		return postgresRepo, postgresRepo.GormRepository, nil
+	case config.DBDriverSQLite:
+		sqliteRepo, err := repository.NewSQLiteRepository(cfg.DB.Path, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sqliteRepo, sqliteRepo.GormRepository, nil
+	case config.DBDriverMemory:
+		slog.Warn("using the in-memory message repository, messages are lost on restart")
+		return repository.NewMemoryRepository(), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown database driver %q", cfg.DB.Driver)
+	}
+}
+
+// newCacheRepository creates the cache selected by cache.driver. The Redis
+// repository is returned separately for health checks and shutdown, and is nil
+// for the other drivers.
+func newCacheRepository(cfg *config.Configuration, m metrics.Metrics) (repository.CacheRepository, *repository.RedisRepository, error) {
+	switch cfg.Cache.Driver {
+	case config.CacheDriverRedis:
+		redisRepo := repository.NewRedisRepository(
+			cfg.Redis.Addr,
+			cfg.Redis.Password.Value(),
+			cfg.Redis.DB,
+			repository.WithRedisMetrics(m),
+			repository.WithRedisTimeout(seconds(cfg.Redis.TimeoutSeconds)),
+		)
+		return redisRepo, redisRepo, nil
+	case config.CacheDriverLRU:
+		return repository.NewLRUCacheRepository(cfg.Cache.Size, repository.WithLRUMetrics(m)), nil, nil
+	case config.CacheDriverNoop:
+		return repository.NewNoopCacheRepository(), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown cache driver %q", cfg.Cache.Driver)
+	}
+}
+
+// newScheduleOptions builds the service options for the configured cron
+// schedule, sending windows and quiet hours; without them the service runs every
+// messageSendInterval minutes around the clock
+func newScheduleOptions(cfg config.AppConfig) ([]services.Option, error) {
+	var opts []services.Option
+
+	if cfg.MessageSendSchedule != "" {
+		location, err := time.LoadLocation(cfg.MessageSendTimezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.MessageSendTimezone, err)
+		}
+		cron, err := schedule.ParseCron(cfg.MessageSendSchedule, location)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, services.WithSchedule(cron))
+	}
+
+	var windows schedule.Windows
+	for i, w := range cfg.SendingWindows {
+		window, err := schedule.ParseWindow(w.Start, w.End, w.Days, w.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("sending window %d: %w", i+1, err)
+		}
+		windows = append(windows, window)
+	}
+	if len(windows) > 0 {
+		opts = append(opts, services.WithSendingWindows(windows))
+	}
+
+	if cfg.QuietHours.Enabled {
+		quietHours, err := recipient.NewQuietHours(cfg.QuietHours.Start, cfg.QuietHours.End, cfg.QuietHours.DefaultTimezone)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, services.WithQuietHours(quietHours))
+	}
+
+	return opts, nil
+}
+
+// newHealthChecker builds the readiness checks for the configured dependencies
+func newHealthChecker(
+	cfg config.HealthConfig,
+	dbDriver string,
+	sqlRepo *repository.GormRepository,
+	redisRepo *repository.RedisRepository,
+	messageClient *clients.MessageClient,
+) *health.Checker {
+	var checks []health.Check
+	// The database and Redis are only checked when they are configured; the
+	// database check is named after the driver, e.g. postgres or sqlite
+	if sqlRepo != nil {
+		checks = append(checks, health.Check{Name: dbDriver, Required: cfg.IsRequired(dbDriver), Probe: sqlRepo.Ping})
+	}
+	if redisRepo != nil {
+		checks = append(checks, health.Check{Name: "redis", Required: cfg.IsRequired("redis"), Probe: redisRepo.Ping})
+	}
+	if cfg.CheckWebhook {
+		checks = append(checks, health.Check{Name: "webhook", Required: cfg.IsRequired("webhook"), Probe: messageClient.Ping})
+	}
+
+	return health.NewChecker(seconds(cfg.TimeoutSeconds), checks...)
+}
+
+// seconds converts a whole-second config value to a time.Duration
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// fatal logs an error and exits the process
+func fatal(msg string, err error) {
+	slog.Error(msg, logging.KeyError, err)
+	os.Exit(1)
 }
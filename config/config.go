@@ -2,90 +2,280 @@ package config
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 )
 
 // Configuration represents the main application configuration
 type Configuration struct {
-	Server ServerConfig `json:"server"`
-	DB     DBConfig     `json:"db"`
-	Redis  RedisConfig  `json:"redis"`
-	App    AppConfig    `json:"app"`
+	Server  ServerConfig  `json:"server"`
+	DB      DBConfig      `json:"db"`
+	Redis   RedisConfig   `json:"redis"`
+	Cache   CacheConfig   `json:"cache"`
+	App     AppConfig     `json:"app"`
+	Metrics MetricsConfig `json:"metrics"`
+	Log     LogConfig     `json:"log"`
+	Tracing TracingConfig `json:"tracing"`
+	Health  HealthConfig  `json:"health"`
 }
 
 // ServerConfig holds the server configuration
 type ServerConfig struct {
 	Port int `json:"port"`
+	// ShutdownTimeoutSeconds bounds how long shutdown waits for in-flight requests and the current batch
+	ShutdownTimeoutSeconds int `json:"shutdownTimeoutSeconds"`
+	// RequestTimeoutSeconds is the deadline for the work done by a single API request, 0 disables it
+	RequestTimeoutSeconds int `json:"requestTimeoutSeconds"`
 }
 
 // DBConfig holds the database configuration
 type DBConfig struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Driver       string `json:"driver"` // postgres, sqlite or memory
+	Host         string `json:"host"`
+	Port         int    `json:"port"`
+	User         string `json:"user"`
+	Password     Secret `json:"password"`
+	PasswordFile string `json:"passwordFile,omitempty"` // read into Password when set, e.g. /run/secrets/db
+	Name         string `json:"name"`
+	Path         string `json:"path"` // database file of the sqlite driver
+	// AutoMigrate applies pending schema migrations at startup; disable it to run "migrate up" separately
+	AutoMigrate bool `json:"autoMigrate"`
+	// QueryTimeoutSeconds is the deadline for a single database call, 0 disables it
+	QueryTimeoutSeconds int `json:"queryTimeoutSeconds"`
 }
 
 // RedisConfig holds the Redis configuration
 type RedisConfig struct {
-	Addr     string `json:"addr"`
-	Password string `json:"password"`
-	DB       int    `json:"db"`
+	Addr         string `json:"addr"`
+	Password     Secret `json:"password"`
+	PasswordFile string `json:"passwordFile,omitempty"` // read into Password when set
+	DB           int    `json:"db"`
+	// TimeoutSeconds is the deadline for a single Redis call, 0 disables it
+	TimeoutSeconds int `json:"timeoutSeconds"`
+}
+
+// Database drivers selectable with db.driver
+const (
+	DBDriverPostgres = "postgres"
+	DBDriverSQLite   = "sqlite"
+	DBDriverMemory   = "memory"
+)
+
+// Cache drivers selectable with cache.driver
+const (
+	CacheDriverRedis = "redis"
+	CacheDriverLRU   = "lru"
+	CacheDriverNoop  = "noop"
+)
+
+// CacheConfig selects the cache implementation for sent message IDs
+type CacheConfig struct {
+	Driver string `json:"driver"` // redis, lru or noop
+	Size   int    `json:"size"`   // maximum number of entries of the lru cache
+}
+
+// MetricsConfig holds the metrics configuration
+type MetricsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// LogConfig holds the logging configuration
+type LogConfig struct {
+	Level  string `json:"level"`  // debug, info, warn or error
+	Format string `json:"format"` // json or text
+}
+
+// TracingConfig holds the OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled"`
+	Endpoint    string  `json:"endpoint"` // OTLP/HTTP endpoint URL; falls back to OTEL_EXPORTER_OTLP_* variables
+	ServiceName string  `json:"serviceName"`
+	SampleRatio float64 `json:"sampleRatio"`
+}
+
+// HealthConfig holds the readiness check configuration
+type HealthConfig struct {
+	// Required lists the dependencies (postgres, redis, webhook) whose failure makes the service not ready
+	Required       []string `json:"required"`
+	CheckWebhook   bool     `json:"checkWebhook"`
+	TimeoutSeconds int      `json:"timeoutSeconds"`
+}
+
+// IsRequired reports whether the named dependency is required for readiness
+func (h HealthConfig) IsRequired(name string) bool {
+	for _, required := range h.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
 }
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
 	MessageBatchSize    int    `json:"messageBatchSize"`
 	WebhookURL          string `json:"webhookUrl"`
-	MaxContentLength    int    `json:"maxContentLength"`
+	MaxContentLength    int    `json:"maxContentLength"` // in characters
 	MessageSendDryRun   bool   `json:"messageSendDryRun"`
 	MessageSendInterval int    `json:"messageSendInterval"`
+	// MessageSendSchedule is a cron expression (minute hour day month weekday)
+	// replacing MessageSendInterval when set, evaluated in MessageSendTimezone
+	MessageSendSchedule string `json:"messageSendSchedule,omitempty"`
+	MessageSendTimezone string `json:"messageSendTimezone,omitempty"` // IANA name, UTC when empty
+	// SendingWindows restrict the scheduled runs, messages are held until a window opens
+	SendingWindows []SendingWindow `json:"sendingWindows,omitempty"`
+	// MaxSegments limits the SMS segments of a message's content, 0 for no limit
+	MaxSegments int `json:"maxSegments,omitempty"`
+	// HighPriorityShare is the share of each batch reserved for high-priority
+	// messages, the rest goes to lower priorities first; 1 sends strictly by
+	// priority, 0 reserves nothing so high priorities only get what is left
+	HighPriorityShare float64 `json:"highPriorityShare"`
+	// DefaultPhoneRegion is the ISO country code phone numbers without a
+	// country code are read in, e.g. TR for 0532...; such numbers are rejected when empty
+	DefaultPhoneRegion string `json:"defaultPhoneRegion"`
+	// Transliteration is the language content is transliterated in by default
+	// to fit GSM-7, e.g. tr for ş→s; content is sent unchanged when empty
+	Transliteration string `json:"transliteration,omitempty"`
+	// QuietHours hold promotional messages back at night in the recipient's timezone
+	QuietHours QuietHoursConfig `json:"quietHours"`
+	// WebhookTimeoutSeconds is the deadline for a single webhook call
+	WebhookTimeoutSeconds int `json:"webhookTimeoutSeconds"`
+}
+
+// SendingWindow is a daily time span in which messages may be sent
+type SendingWindow struct {
+	Start    string   `json:"start"`              // HH:MM
+	End      string   `json:"end"`                // HH:MM, at or before start for windows past midnight
+	Days     []string `json:"days,omitempty"`     // e.g. ["mon-sat"], every day when empty
+	Timezone string   `json:"timezone,omitempty"` // IANA name, UTC when empty
 }
 
-// LoadConfig loads the configuration from config.json or returns the default configuration
-func LoadConfig() *Configuration {
+// QuietHoursConfig holds the recipient-local quiet hours for promotional messages
+type QuietHoursConfig struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"` // HH:MM
+	End     string `json:"end"`   // HH:MM
+	// DefaultTimezone is used when the timezone can't be derived from the phone number
+	DefaultTimezone string `json:"defaultTimezone,omitempty"` // IANA name, UTC when empty
+}
+
+// LoadConfig loads the configuration from config.json or returns the default
+// configuration. It fails when a configured secret file can't be read.
+func LoadConfig() (*Configuration, error) {
+	return loadConfig("config.json")
+}
+
+// loadConfig loads the configuration from the given file and resolves secret files
+func loadConfig(path string) (*Configuration, error) {
 	// Default configuration
 	config := &Configuration{
 		Server: ServerConfig{
-			Port: 8080,
+			Port:                   8080,
+			ShutdownTimeoutSeconds: 30,
+			RequestTimeoutSeconds:  10,
 		},
 		DB: DBConfig{
-			Host:     "localhost",
-			Port:     5432,
-			User:     "postgres",
-			Password: "postgres",
-			Name:     "messaging",
+			Driver:              DBDriverPostgres,
+			Host:                "localhost",
+			Port:                5432,
+			User:                "postgres",
+			Password:            "postgres",
+			Name:                "messaging",
+			Path:                "messages.db",
+			AutoMigrate:         true,
+			QueryTimeoutSeconds: 5,
 		},
 		Redis: RedisConfig{
-			Addr:     "localhost:6379",
-			Password: "",
-			DB:       0,
+			Addr:           "localhost:6379",
+			Password:       "",
+			DB:             0,
+			TimeoutSeconds: 1,
+		},
+		Cache: CacheConfig{
+			Driver: CacheDriverRedis,
+			Size:   10000,
 		},
 		App: AppConfig{
-			MessageBatchSize:    2,
-			WebhookURL:          "https://webhook.site/",
-			MaxContentLength:    1000,
-			MessageSendDryRun:   false,
-			MessageSendInterval: 2,
+			MessageBatchSize:      2,
+			HighPriorityShare:     0.8,
+			DefaultPhoneRegion:    "TR",
+			WebhookURL:            "https://webhook.site/",
+			MaxContentLength:      1000,
+			MessageSendDryRun:     false,
+			MessageSendInterval:   2,
+			WebhookTimeoutSeconds: 10,
+			QuietHours: QuietHoursConfig{
+				Enabled: true,
+				Start:   "21:00",
+				End:     "09:00",
+			},
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			ServiceName: "messaging-system",
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			Required:       []string{"postgres"},
+			CheckWebhook:   false,
+			TimeoutSeconds: 2,
 		},
 	}
 
 	// Try to load configuration from the file
-	configFile, err := os.Open("config.json")
+	if err := config.decode(path); err != nil {
+		slog.Warn("could not load config file, using default configuration", "path", path, "error", err)
+	}
+
+	// A secret file that was configured must be used, falling back to the
+	// inline or default value would connect with the wrong credentials
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// decode reads the configuration file over the defaults
+func (c *Configuration) decode(path string) error {
+	configFile, err := os.Open(path)
 	if err != nil {
-		log.Printf("Warning: Could not open config file: %v. Using default configuration.", err)
-		return config
+		return err
 	}
 	defer configFile.Close()
 
-	jsonParser := json.NewDecoder(configFile)
-	err = jsonParser.Decode(config)
-	if err != nil {
-		log.Printf("Warning: Could not parse config file: %v. Using default configuration.", err)
-		return config
+	return json.NewDecoder(configFile).Decode(c)
+}
+
+// resolveSecrets replaces secret values with the contents of their *File
+// counterparts when those are configured
+func (c *Configuration) resolveSecrets() error {
+	secrets := []struct {
+		name  string
+		file  string
+		value *Secret
+	}{
+		{"db.password", c.DB.PasswordFile, &c.DB.Password},
+		{"redis.password", c.Redis.PasswordFile, &c.Redis.Password},
 	}
 
-	return config
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		value, err := readSecretFile(secret.file)
+		if err != nil {
+			return fmt.Errorf("could not load %s: %w", secret.name, err)
+		}
+		*secret.value = value
+	}
+	return nil
 }
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadConfigSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	dbSecret := writeFile(t, dir, "db", "db-secret\n")
+	redisSecret := writeFile(t, dir, "redis", "redis-secret")
+
+	configPath := writeFile(t, dir, "config.json", fmt.Sprintf(`{
+		"db": {"password": "inline", "passwordFile": %q},
+		"redis": {"passwordFile": %q}
+	}`, dbSecret, redisSecret))
+
+	cfg, err := loadConfig(configPath)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "db-secret", cfg.DB.Password.Value())
+	assert.Equal(t, "redis-secret", cfg.Redis.Password.Value())
+}
+
+func TestLoadConfigMissingSecretFileFails(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeFile(t, dir, "config.json", `{
+		"db": {"password": "inline", "passwordFile": "/does/not/exist"}
+	}`)
+
+	_, err := loadConfig(configPath)
+
+	assert.ErrorContains(t, err, "db.password")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadConfigResolvesSecretsOfInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeFile(t, dir, "config.json", `{
+		"redis": {"passwordFile": "/does/not/exist"},
+		"server": {"port": "not a number"}
+	}`)
+
+	_, err := loadConfig(configPath)
+
+	assert.ErrorContains(t, err, "redis.password")
+}
+
+func TestLoadConfigWithoutFile(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.json"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.DB.Password.Value())
+}
+
+func TestSecretIsRedacted(t *testing.T) {
+	cfg := &Configuration{
+		DB:    DBConfig{User: "admin", Password: "psw123"},
+		Redis: RedisConfig{Password: "hunter2"},
+	}
+
+	for _, formatted := range []string{
+		fmt.Sprintf("%v", *cfg),
+		fmt.Sprintf("%+v", *cfg),
+		fmt.Sprintf("%#v", *cfg),
+		fmt.Sprint(cfg.DB.Password),
+	} {
+		assert.NotContains(t, formatted, "psw123")
+		assert.NotContains(t, formatted, "hunter2")
+	}
+
+	data, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "psw123")
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), redactedSecret)
+
+	// Empty secrets stay empty so it is obvious that nothing is configured
+	assert.Equal(t, "", Secret("").String())
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// redactedSecret is printed in place of a secret value
+const redactedSecret = "[REDACTED]"
+
+// Secret holds a sensitive configuration value such as a password or token.
+// Its String, GoString and MarshalJSON methods never reveal the value, so a
+// Secret can be logged or serialized safely. Use Value to read the plaintext.
+type Secret string
+
+// Value returns the plaintext secret
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String returns a redacted representation of the secret
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+// GoString returns a redacted representation of the secret for %#v
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalJSON encodes the secret in redacted form
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// readSecretFile reads a secret from a file such as a Docker or Kubernetes
+// secret mount. A single trailing newline is trimmed.
+func readSecretFile(path string) (Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file %s: %w", path, err)
+	}
+	return Secret(strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")), nil
+}
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestCheckerAllUp(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "postgres", Required: true, Probe: up},
+		Check{Name: "redis", Required: false, Probe: up},
+	)
+
+	report := checker.Run(context.Background())
+
+	assert.Equal(t, StatusOK, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, StatusUp, report.Checks["postgres"].Status)
+	assert.True(t, report.Checks["postgres"].Required)
+	assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+}
+
+func TestCheckerOptionalDown(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "postgres", Required: true, Probe: up},
+		Check{Name: "redis", Required: false, Probe: down},
+	)
+
+	report := checker.Run(context.Background())
+
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.True(t, report.Ready())
+	assert.Equal(t, StatusDown, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+}
+
+func TestCheckerRequiredDown(t *testing.T) {
+	checker := NewChecker(time.Second,
+		Check{Name: "postgres", Required: true, Probe: down},
+		Check{Name: "redis", Required: false, Probe: down},
+	)
+
+	report := checker.Run(context.Background())
+
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.False(t, report.Ready())
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	checker := NewChecker(20*time.Millisecond, Check{Name: "webhook", Required: true, Probe: hang})
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, StatusDown, report.Checks["webhook"].Status)
+	assert.Contains(t, report.Checks["webhook"].Error, "deadline exceeded")
+}
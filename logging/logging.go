@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/alper.meric/messaging-system/config"
+)
+
+// Attribute keys shared by all log lines
+const (
+	KeyRequestID  = "request_id"
+	KeyTraceID    = "trace_id"
+	KeyMessageID  = "message_id"
+	KeyExternalID = "external_id"
+	KeyProvider   = "provider"
+	KeyAttempt    = "attempt"
+	KeyComponent  = "component"
+	KeyError      = "error"
+)
+
+type contextKey struct{}
+
+// Setup creates the application logger from configuration and installs it as
+// the default slog logger, which also redirects the standard log package
+func Setup(cfg config.LogConfig) *slog.Logger {
+	logger := New(os.Stdout, cfg)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// New creates a logger writing to w with the configured level and format
+func New(w io.Writer, cfg config.LogConfig) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(cfg.Level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "text") {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(handler)
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog level,
+// defaulting to info for unknown values
+func ParseLevel(level string) slog.Level {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return l
+}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric names used as keys by MemoryMetrics
+const (
+	MessagesSent     = "messages_sent"
+	MessagesFailed   = "messages_failed"
+	MessagesRejected = "messages_rejected"
+	SendLatency      = "send_latency"
+	QueueDepth       = "queue_depth"
+	ServiceRunning   = "service_running"
+	CacheHits        = "cache_hits"
+	CacheMisses      = "cache_misses"
+	HTTPRequests     = "http_requests"
+)
+
+// MemoryMetrics keeps metrics in memory so tests can assert on them
+type MemoryMetrics struct {
+	mutex    sync.Mutex
+	counters map[string]int
+	gauges   map[string]float64
+}
+
+// NewMemoryMetrics creates an empty MemoryMetrics instance
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		counters: make(map[string]int),
+		gauges:   make(map[string]float64),
+	}
+}
+
+// key joins a metric name and its label values
+func key(name string, labels ...string) string {
+	return strings.Join(append([]string{name}, labels...), "|")
+}
+
+func (m *MemoryMetrics) inc(name string, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.counters[key(name, labels...)]++
+}
+
+func (m *MemoryMetrics) set(name string, value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.gauges[name] = value
+}
+
+// Count returns the value of a counter for the given label values. Histograms
+// are counted by number of observations.
+func (m *MemoryMetrics) Count(name string, labels ...string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.counters[key(name, labels...)]
+}
+
+// Gauge returns the current value of a gauge
+func (m *MemoryMetrics) Gauge(name string) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.gauges[name]
+}
+
+// MessageSent counts a message accepted by the provider
+func (m *MemoryMetrics) MessageSent(provider string) {
+	m.inc(MessagesSent, provider)
+}
+
+// MessageFailed counts a message that could not be sent
+func (m *MemoryMetrics) MessageFailed(provider, errorClass string) {
+	m.inc(MessagesFailed, provider, errorClass)
+}
+
+// MessageRejected counts a message that was refused
+func (m *MemoryMetrics) MessageRejected(provider, errorClass string) {
+	m.inc(MessagesRejected, provider, errorClass)
+}
+
+// ObserveSendLatency counts a provider call
+func (m *MemoryMetrics) ObserveSendLatency(provider string, _ time.Duration) {
+	m.inc(SendLatency, provider)
+}
+
+// SetQueueDepth records the number of messages waiting to be sent
+func (m *MemoryMetrics) SetQueueDepth(depth int) {
+	m.set(QueueDepth, float64(depth))
+}
+
+// SetServiceRunning records whether the message service is running
+func (m *MemoryMetrics) SetServiceRunning(running bool) {
+	value := 0.0
+	if running {
+		value = 1
+	}
+	m.set(ServiceRunning, value)
+}
+
+// CacheHit counts a cache lookup that found an entry
+func (m *MemoryMetrics) CacheHit() {
+	m.inc(CacheHits)
+}
+
+// CacheMiss counts a cache lookup that found nothing
+func (m *MemoryMetrics) CacheMiss() {
+	m.inc(CacheMisses)
+}
+
+// ObserveHTTPRequest counts a served HTTP request
+func (m *MemoryMetrics) ObserveHTTPRequest(method, route string, status int, _ time.Duration) {
+	m.inc(HTTPRequests, method, route, fmt.Sprint(status))
+}
+
+// Handler writes all recorded values as plain text
+func (m *MemoryMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		lines := make([]string, 0, len(m.counters)+len(m.gauges))
+		for name, value := range m.counters {
+			lines = append(lines, fmt.Sprintf("%s %d", name, value))
+		}
+		for name, value := range m.gauges {
+			lines = append(lines, fmt.Sprintf("%s %g", name, value))
+		}
+		sort.Strings(lines)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, strings.Join(lines, "\n"))
+	})
+}
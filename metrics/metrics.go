@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Metrics records application metrics. Implementations must be safe for
+// concurrent use.
+type Metrics interface {
+	// MessageSent counts a message accepted by the provider
+	MessageSent(provider string)
+
+	// MessageFailed counts a message that could not be sent and will be retried
+	MessageFailed(provider, errorClass string)
+
+	// MessageRejected counts a message that was refused and will not succeed as is
+	MessageRejected(provider, errorClass string)
+
+	// ObserveSendLatency records how long a provider call took
+	ObserveSendLatency(provider string, duration time.Duration)
+
+	// SetQueueDepth records the number of messages waiting to be sent
+	SetQueueDepth(depth int)
+
+	// SetServiceRunning records whether the message service is running
+	SetServiceRunning(running bool)
+
+	// CacheHit counts a cache lookup that found an entry
+	CacheHit()
+
+	// CacheMiss counts a cache lookup that found nothing
+	CacheMiss()
+
+	// ObserveHTTPRequest records a served HTTP request
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+
+	// Handler exposes the collected metrics over HTTP
+	Handler() http.Handler
+}
+
+// noopMetrics discards all metrics
+type noopMetrics struct{}
+
+// NewNoop returns a Metrics implementation that records nothing
+func NewNoop() Metrics {
+	return noopMetrics{}
+}
+
+func (noopMetrics) MessageSent(string)                                    {}
+func (noopMetrics) MessageFailed(string, string)                          {}
+func (noopMetrics) MessageRejected(string, string)                        {}
+func (noopMetrics) ObserveSendLatency(string, time.Duration)              {}
+func (noopMetrics) SetQueueDepth(int)                                     {}
+func (noopMetrics) SetServiceRunning(bool)                                {}
+func (noopMetrics) CacheHit()                                             {}
+func (noopMetrics) CacheMiss()                                            {}
+func (noopMetrics) ObserveHTTPRequest(string, string, int, time.Duration) {}
+
+func (noopMetrics) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "messaging"
+
+// PrometheusMetrics implements Metrics using Prometheus collectors
+type PrometheusMetrics struct {
+	registry       *prometheus.Registry
+	messagesSent   *prometheus.CounterVec
+	messagesFailed *prometheus.CounterVec
+	messagesReject *prometheus.CounterVec
+	sendLatency    *prometheus.HistogramVec
+	queueDepth     prometheus.Gauge
+	serviceRunning prometheus.Gauge
+	cacheLookups   *prometheus.CounterVec
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+}
+
+// NewPrometheusMetrics creates a PrometheusMetrics instance with its own registry,
+// including the standard Go runtime and process collectors
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_sent_total",
+			Help:      "Number of messages accepted by the provider.",
+		}, []string{"provider"}),
+		messagesFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_failed_total",
+			Help:      "Number of message send attempts that failed and will be retried.",
+		}, []string{"provider", "error_class"}),
+		messagesReject: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_rejected_total",
+			Help:      "Number of messages refused by validation or by the provider.",
+		}, []string{"provider", "error_class"}),
+		sendLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "send_duration_seconds",
+			Help:      "Latency of provider send calls.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider"}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Number of messages waiting to be sent.",
+		}),
+		serviceRunning: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "service_running",
+			Help:      "Whether the message service is running (1) or stopped (0).",
+		}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Number of message cache lookups by result.",
+		}, []string{"result"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests served.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.messagesSent,
+		m.messagesFailed,
+		m.messagesReject,
+		m.sendLatency,
+		m.queueDepth,
+		m.serviceRunning,
+		m.cacheLookups,
+		m.httpRequests,
+		m.httpDuration,
+	)
+
+	return m
+}
+
+// Registry returns the registry holding all collectors
+func (m *PrometheusMetrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// MessageSent counts a message accepted by the provider
+func (m *PrometheusMetrics) MessageSent(provider string) {
+	m.messagesSent.WithLabelValues(provider).Inc()
+}
+
+// MessageFailed counts a message that could not be sent
+func (m *PrometheusMetrics) MessageFailed(provider, errorClass string) {
+	m.messagesFailed.WithLabelValues(provider, errorClass).Inc()
+}
+
+// MessageRejected counts a message that was refused
+func (m *PrometheusMetrics) MessageRejected(provider, errorClass string) {
+	m.messagesReject.WithLabelValues(provider, errorClass).Inc()
+}
+
+// ObserveSendLatency records how long a provider call took
+func (m *PrometheusMetrics) ObserveSendLatency(provider string, duration time.Duration) {
+	m.sendLatency.WithLabelValues(provider).Observe(duration.Seconds())
+}
+
+// SetQueueDepth records the number of messages waiting to be sent
+func (m *PrometheusMetrics) SetQueueDepth(depth int) {
+	m.queueDepth.Set(float64(depth))
+}
+
+// SetServiceRunning records whether the message service is running
+func (m *PrometheusMetrics) SetServiceRunning(running bool) {
+	if running {
+		m.serviceRunning.Set(1)
+	} else {
+		m.serviceRunning.Set(0)
+	}
+}
+
+// CacheHit counts a cache lookup that found an entry
+func (m *PrometheusMetrics) CacheHit() {
+	m.cacheLookups.WithLabelValues("hit").Inc()
+}
+
+// CacheMiss counts a cache lookup that found nothing
+func (m *PrometheusMetrics) CacheMiss() {
+	m.cacheLookups.WithLabelValues("miss").Inc()
+}
+
+// ObserveHTTPRequest records a served HTTP request
+func (m *PrometheusMetrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// Handler exposes the registry in the Prometheus text format
+func (m *PrometheusMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
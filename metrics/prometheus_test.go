@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	m := NewPrometheusMetrics()
+
+	m.MessageSent("webhook")
+	m.MessageSent("webhook")
+	m.MessageFailed("webhook", "timeout")
+	m.MessageRejected("webhook", "http_4xx")
+	m.ObserveSendLatency("webhook", 120*time.Millisecond)
+	m.SetQueueDepth(7)
+	m.SetServiceRunning(true)
+	m.CacheHit()
+	m.CacheMiss()
+	m.ObserveHTTPRequest("GET", "/api/messages", 200, 5*time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.messagesSent.WithLabelValues("webhook")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.messagesFailed.WithLabelValues("webhook", "timeout")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.messagesReject.WithLabelValues("webhook", "http_4xx")))
+	assert.Equal(t, 7.0, testutil.ToFloat64(m.queueDepth))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.serviceRunning))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.cacheLookups.WithLabelValues("hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/messages", "200")))
+
+	// Exposition format
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, string(body), `messaging_messages_sent_total{provider="webhook"} 2`)
+	assert.Contains(t, string(body), `messaging_send_duration_seconds_count{provider="webhook"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
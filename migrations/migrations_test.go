@@ -0,0 +1,185 @@
+package migrations
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		migrations, err := load(dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+
+		for i, m := range migrations {
+			assert.Equal(t, i+1, m.Version, "%s migrations must be numbered without gaps", dialect)
+			assert.NotEmpty(t, m.Up, "%s migration %d has no up script", dialect, m.Version)
+			assert.NotEmpty(t, m.Down, "%s migration %d has no down script", dialect, m.Version)
+		}
+	}
+
+	// Both dialects must describe the same schema history
+	postgresMigrations, _ := load("postgres")
+	sqliteMigrations, _ := load("sqlite")
+	require.Equal(t, len(postgresMigrations), len(sqliteMigrations))
+	for i := range postgresMigrations {
+		assert.Equal(t, postgresMigrations[i].Name, sqliteMigrations[i].Name)
+	}
+
+	_, err := load("mysql")
+	assert.Error(t, err)
+}
+
+func TestUpDownStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+	migrator, err := New(db)
+	require.NoError(t, err)
+	total := len(migrator.Migrations())
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, total)
+	for _, s := range statuses {
+		assert.False(t, s.Applied)
+	}
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, total)
+	assert.True(t, db.Migrator().HasTable("messages"))
+
+	// Running again is a no-op
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.True(t, s.Applied)
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	// Reverting everything drops the schema again
+	reverted, err := migrator.Down(ctx, total)
+	require.NoError(t, err)
+	assert.Len(t, reverted, total)
+	assert.Equal(t, 1, reverted[len(reverted)-1].Version, "migrations are reverted newest first")
+	assert.False(t, db.Migrator().HasTable("messages"))
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, total)
+}
+
+func TestDownOneStep(t *testing.T) {
+	ctx := context.Background()
+	migrator, err := New(openSQLite(t))
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	last := statuses[len(statuses)-1]
+	assert.Equal(t, reverted[0].Version, last.Version)
+	assert.False(t, last.Applied)
+}
+
+func TestUpAdoptsAutoMigratedSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	// Databases created before versioned migrations already have the table
+	require.NoError(t, db.Exec(`CREATE TABLE messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		content TEXT NOT NULL,
+		phone_number VARCHAR(20) NOT NULL,
+		is_sent NUMERIC DEFAULT false,
+		sent_at DATETIME,
+		external_msg_id TEXT,
+		created_at DATETIME,
+		updated_at DATETIME,
+		deleted_at DATETIME
+	)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO messages (content, phone_number) VALUES ('hello', '+905551112233')`).Error)
+
+	migrator, err := New(db)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	var count int64
+	require.NoError(t, db.Table("messages").Count(&count).Error)
+	assert.Equal(t, int64(1), count, "existing rows must be kept")
+}
+
+// TestPostgresConcurrentUp runs against the database in TEST_POSTGRES_DSN and
+// checks that replicas migrating at the same time wait for each other
+func TestPostgresConcurrentUp(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer func() {
+				if sqlDB, err := db.DB(); err == nil {
+					_ = sqlDB.Close()
+				}
+			}()
+
+			migrator, err := New(db)
+			if err != nil {
+				errs <- err
+				return
+			}
+			_, err = migrator.Up(context.Background())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,15 @@
+-- Matches the schema previously created by GORM's AutoMigrate, so existing
+-- databases are adopted without changes
+CREATE TABLE IF NOT EXISTS messages (
+    id BIGSERIAL PRIMARY KEY,
+    content TEXT NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    is_sent BOOLEAN DEFAULT FALSE,
+    sent_at TIMESTAMPTZ,
+    external_msg_id TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages (deleted_at);
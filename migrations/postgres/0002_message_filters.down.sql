@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_messages_content_trgm;
+DROP INDEX IF EXISTS idx_messages_phone_number;
+DROP INDEX IF EXISTS idx_messages_external_msg_id;
+DROP INDEX IF EXISTS idx_messages_failed_at;
+DROP INDEX IF EXISTS idx_messages_sent_at;
+DROP INDEX IF EXISTS idx_messages_created_at;
+DROP INDEX IF EXISTS idx_messages_queue;
+
+ALTER TABLE messages DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE messages DROP COLUMN IF EXISTS last_error;
+ALTER TABLE messages DROP COLUMN IF EXISTS attempts;
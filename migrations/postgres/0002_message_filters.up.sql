@@ -0,0 +1,30 @@
+-- Delivery tracking: attempts, last error and permanent failures
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ;
+
+-- Queue scan of GetUnsentMessages
+CREATE INDEX IF NOT EXISTS idx_messages_queue ON messages (created_at, id)
+    WHERE is_sent = FALSE AND failed_at IS NULL AND deleted_at IS NULL;
+
+-- Listing filters and sort fields
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages (sent_at, id);
+CREATE INDEX IF NOT EXISTS idx_messages_failed_at ON messages (failed_at) WHERE failed_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_external_msg_id ON messages (external_msg_id);
+-- varchar_pattern_ops serves both exact and prefix (LIKE 'x%') lookups
+CREATE INDEX IF NOT EXISTS idx_messages_phone_number ON messages (phone_number varchar_pattern_ops);
+
+-- Trigram index for case-insensitive substring search on content. Creating the
+-- pg_trgm extension needs privileges the application role usually lacks, so it
+-- is a prerequisite installed outside the migrations; without it the content
+-- filter still works, scanning the table instead.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS idx_messages_content_trgm ON messages USING gin (content gin_trgm_ops);
+    ELSE
+        RAISE NOTICE 'pg_trgm is not installed, skipping idx_messages_content_trgm';
+    END IF;
+END
+$$;
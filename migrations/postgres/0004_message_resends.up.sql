@@ -0,0 +1,4 @@
+-- parent_id: the original message a manual resend was copied from
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES messages (id);
+
+CREATE INDEX IF NOT EXISTS idx_messages_parent_id ON messages (parent_id) WHERE parent_id IS NOT NULL;
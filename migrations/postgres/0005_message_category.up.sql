@@ -0,0 +1,3 @@
+-- category: transactional messages are always sent, promotional ones are held
+-- back during the recipient's quiet hours
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT 'transactional';
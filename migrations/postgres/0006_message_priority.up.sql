@@ -0,0 +1,7 @@
+-- priority: higher priorities are sent first, see MessagePriority* in models
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+-- The queue is read by priority before age
+DROP INDEX IF EXISTS idx_messages_queue;
+CREATE INDEX IF NOT EXISTS idx_messages_queue ON messages (priority DESC, created_at, id)
+    WHERE is_sent = FALSE AND failed_at IS NULL AND cancelled_at IS NULL AND deleted_at IS NULL;
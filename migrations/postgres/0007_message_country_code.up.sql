@@ -0,0 +1,3 @@
+-- country_code: ISO 3166-1 alpha-2 country of the recipient, detected when the
+-- phone number is normalised to E.164
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS country_code VARCHAR(2);
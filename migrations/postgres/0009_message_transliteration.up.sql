@@ -0,0 +1,3 @@
+-- transliteration: language the content is transliterated in to fit GSM-7,
+-- 'off' to send it unchanged, NULL for the configured default
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS transliteration VARCHAR(5);
@@ -0,0 +1,15 @@
+-- Matches the schema previously created by GORM's AutoMigrate, so existing
+-- databases are adopted without changes
+CREATE TABLE IF NOT EXISTS messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    content TEXT NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    is_sent NUMERIC DEFAULT FALSE,
+    sent_at DATETIME,
+    external_msg_id TEXT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_deleted_at ON messages (deleted_at);
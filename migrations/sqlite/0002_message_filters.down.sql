@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_messages_phone_number;
+DROP INDEX IF EXISTS idx_messages_external_msg_id;
+DROP INDEX IF EXISTS idx_messages_failed_at;
+DROP INDEX IF EXISTS idx_messages_sent_at;
+DROP INDEX IF EXISTS idx_messages_created_at;
+DROP INDEX IF EXISTS idx_messages_queue;
+
+ALTER TABLE messages DROP COLUMN failed_at;
+ALTER TABLE messages DROP COLUMN last_error;
+ALTER TABLE messages DROP COLUMN attempts;
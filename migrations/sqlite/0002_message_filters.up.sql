@@ -0,0 +1,15 @@
+-- Delivery tracking: attempts, last error and permanent failures
+ALTER TABLE messages ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE messages ADD COLUMN last_error TEXT;
+ALTER TABLE messages ADD COLUMN failed_at DATETIME;
+
+-- Queue scan of GetUnsentMessages
+CREATE INDEX IF NOT EXISTS idx_messages_queue ON messages (created_at, id)
+    WHERE is_sent = FALSE AND failed_at IS NULL AND deleted_at IS NULL;
+
+-- Listing filters and sort fields
+CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages (sent_at, id);
+CREATE INDEX IF NOT EXISTS idx_messages_failed_at ON messages (failed_at) WHERE failed_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_external_msg_id ON messages (external_msg_id);
+CREATE INDEX IF NOT EXISTS idx_messages_phone_number ON messages (phone_number);
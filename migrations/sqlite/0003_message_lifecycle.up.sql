@@ -0,0 +1,11 @@
+-- claimed_at: taken by a worker for sending, guards edits against in-flight sends
+-- cancelled_at: cancelled through the API, will not be sent
+-- scheduled_at: not to be sent before this time
+ALTER TABLE messages ADD COLUMN claimed_at DATETIME;
+ALTER TABLE messages ADD COLUMN cancelled_at DATETIME;
+ALTER TABLE messages ADD COLUMN scheduled_at DATETIME;
+
+-- The queue no longer contains cancelled messages
+DROP INDEX IF EXISTS idx_messages_queue;
+CREATE INDEX IF NOT EXISTS idx_messages_queue ON messages (created_at, id)
+    WHERE is_sent = FALSE AND failed_at IS NULL AND cancelled_at IS NULL AND deleted_at IS NULL;
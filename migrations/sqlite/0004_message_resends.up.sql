@@ -0,0 +1,5 @@
+-- parent_id: the original message a manual resend was copied from. Without a
+-- REFERENCES clause, which would keep SQLite from dropping the column again.
+ALTER TABLE messages ADD COLUMN parent_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_messages_parent_id ON messages (parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_messages_queue;
+CREATE INDEX IF NOT EXISTS idx_messages_queue ON messages (created_at, id)
+    WHERE is_sent = FALSE AND failed_at IS NULL AND cancelled_at IS NULL AND deleted_at IS NULL;
+
+ALTER TABLE messages DROP COLUMN priority;
@@ -0,0 +1,4 @@
+-- encoding: GSM-7 or UCS-2, the SMS encoding the content needs
+-- segments: number of concatenated SMS segments the content is sent in
+ALTER TABLE messages ADD COLUMN encoding VARCHAR(5);
+ALTER TABLE messages ADD COLUMN segments INTEGER;
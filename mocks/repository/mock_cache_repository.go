@@ -3,6 +3,9 @@
 package mocks
 
 import (
+	context "context"
+
+	repository "github.com/alper.meric/messaging-system/repository"
 	mock "github.com/stretchr/testify/mock"
 
 	time "time"
@@ -21,17 +24,17 @@ func (_m *CacheRepository) EXPECT() *CacheRepository_Expecter {
 	return &CacheRepository_Expecter{mock: &_m.Mock}
 }
 
-// CacheMessageID provides a mock function with given fields: messageID, sentAt
-func (_m *CacheRepository) CacheMessageID(messageID string, sentAt time.Time) error {
-	ret := _m.Called(messageID, sentAt)
+// CacheMessageID provides a mock function with given fields: ctx, externalID, messageID, sentAt
+func (_m *CacheRepository) CacheMessageID(ctx context.Context, externalID string, messageID int, sentAt time.Time) error {
+	ret := _m.Called(ctx, externalID, messageID, sentAt)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CacheMessageID")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
-		r0 = rf(messageID, sentAt)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Time) error); ok {
+		r0 = rf(ctx, externalID, messageID, sentAt)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -45,15 +48,17 @@ type CacheRepository_CacheMessageID_Call struct {
 }
 
 // CacheMessageID is a helper method to define mock.On call
-//   - messageID string
+//   - ctx context.Context
+//   - externalID string
+//   - messageID int
 //   - sentAt time.Time
-func (_e *CacheRepository_Expecter) CacheMessageID(messageID interface{}, sentAt interface{}) *CacheRepository_CacheMessageID_Call {
-	return &CacheRepository_CacheMessageID_Call{Call: _e.mock.On("CacheMessageID", messageID, sentAt)}
+func (_e *CacheRepository_Expecter) CacheMessageID(ctx interface{}, externalID interface{}, messageID interface{}, sentAt interface{}) *CacheRepository_CacheMessageID_Call {
+	return &CacheRepository_CacheMessageID_Call{Call: _e.mock.On("CacheMessageID", ctx, externalID, messageID, sentAt)}
 }
 
-func (_c *CacheRepository_CacheMessageID_Call) Run(run func(messageID string, sentAt time.Time)) *CacheRepository_CacheMessageID_Call {
+func (_c *CacheRepository_CacheMessageID_Call) Run(run func(ctx context.Context, externalID string, messageID int, sentAt time.Time)) *CacheRepository_CacheMessageID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(time.Time))
+		run(args[0].(context.Context), args[1].(string), args[2].(int), args[3].(time.Time))
 	})
 	return _c
 }
@@ -63,32 +68,32 @@ func (_c *CacheRepository_CacheMessageID_Call) Return(_a0 error) *CacheRepositor
 	return _c
 }
 
-func (_c *CacheRepository_CacheMessageID_Call) RunAndReturn(run func(string, time.Time) error) *CacheRepository_CacheMessageID_Call {
+func (_c *CacheRepository_CacheMessageID_Call) RunAndReturn(run func(context.Context, string, int, time.Time) error) *CacheRepository_CacheMessageID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetCachedMessage provides a mock function with given fields: messageID
-func (_m *CacheRepository) GetCachedMessage(messageID string) (time.Time, error) {
-	ret := _m.Called(messageID)
+// GetCachedMessage provides a mock function with given fields: ctx, externalID
+func (_m *CacheRepository) GetCachedMessage(ctx context.Context, externalID string) (repository.CachedMessage, error) {
+	ret := _m.Called(ctx, externalID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCachedMessage")
 	}
 
-	var r0 time.Time
+	var r0 repository.CachedMessage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (time.Time, error)); ok {
-		return rf(messageID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (repository.CachedMessage, error)); ok {
+		return rf(ctx, externalID)
 	}
-	if rf, ok := ret.Get(0).(func(string) time.Time); ok {
-		r0 = rf(messageID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) repository.CachedMessage); ok {
+		r0 = rf(ctx, externalID)
 	} else {
-		r0 = ret.Get(0).(time.Time)
+		r0 = ret.Get(0).(repository.CachedMessage)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(messageID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, externalID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -102,24 +107,25 @@ type CacheRepository_GetCachedMessage_Call struct {
 }
 
 // GetCachedMessage is a helper method to define mock.On call
-//   - messageID string
-func (_e *CacheRepository_Expecter) GetCachedMessage(messageID interface{}) *CacheRepository_GetCachedMessage_Call {
-	return &CacheRepository_GetCachedMessage_Call{Call: _e.mock.On("GetCachedMessage", messageID)}
+//   - ctx context.Context
+//   - externalID string
+func (_e *CacheRepository_Expecter) GetCachedMessage(ctx interface{}, externalID interface{}) *CacheRepository_GetCachedMessage_Call {
+	return &CacheRepository_GetCachedMessage_Call{Call: _e.mock.On("GetCachedMessage", ctx, externalID)}
 }
 
-func (_c *CacheRepository_GetCachedMessage_Call) Run(run func(messageID string)) *CacheRepository_GetCachedMessage_Call {
+func (_c *CacheRepository_GetCachedMessage_Call) Run(run func(ctx context.Context, externalID string)) *CacheRepository_GetCachedMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *CacheRepository_GetCachedMessage_Call) Return(_a0 time.Time, _a1 error) *CacheRepository_GetCachedMessage_Call {
+func (_c *CacheRepository_GetCachedMessage_Call) Return(_a0 repository.CachedMessage, _a1 error) *CacheRepository_GetCachedMessage_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *CacheRepository_GetCachedMessage_Call) RunAndReturn(run func(string) (time.Time, error)) *CacheRepository_GetCachedMessage_Call {
+func (_c *CacheRepository_GetCachedMessage_Call) RunAndReturn(run func(context.Context, string) (repository.CachedMessage, error)) *CacheRepository_GetCachedMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
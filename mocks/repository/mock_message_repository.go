@@ -3,8 +3,14 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/alper.meric/messaging-system/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repository "github.com/alper.meric/messaging-system/repository"
+
+	time "time"
 )
 
 // MessageRepository is an autogenerated mock type for the MessageRepository type
@@ -20,9 +26,9 @@ func (_m *MessageRepository) EXPECT() *MessageRepository_Expecter {
 	return &MessageRepository_Expecter{mock: &_m.Mock}
 }
 
-// AddMessage provides a mock function with given fields: message
-func (_m *MessageRepository) AddMessage(message models.Message) (int, error) {
-	ret := _m.Called(message)
+// AddMessage provides a mock function with given fields: ctx, message
+func (_m *MessageRepository) AddMessage(ctx context.Context, message models.Message) (int, error) {
+	ret := _m.Called(ctx, message)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddMessage")
@@ -30,17 +36,17 @@ func (_m *MessageRepository) AddMessage(message models.Message) (int, error) {
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.Message) (int, error)); ok {
-		return rf(message)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Message) (int, error)); ok {
+		return rf(ctx, message)
 	}
-	if rf, ok := ret.Get(0).(func(models.Message) int); ok {
-		r0 = rf(message)
+	if rf, ok := ret.Get(0).(func(context.Context, models.Message) int); ok {
+		r0 = rf(ctx, message)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(models.Message) error); ok {
-		r1 = rf(message)
+	if rf, ok := ret.Get(1).(func(context.Context, models.Message) error); ok {
+		r1 = rf(ctx, message)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -54,14 +60,15 @@ type MessageRepository_AddMessage_Call struct {
 }
 
 // AddMessage is a helper method to define mock.On call
+//   - ctx context.Context
 //   - message models.Message
-func (_e *MessageRepository_Expecter) AddMessage(message interface{}) *MessageRepository_AddMessage_Call {
-	return &MessageRepository_AddMessage_Call{Call: _e.mock.On("AddMessage", message)}
+func (_e *MessageRepository_Expecter) AddMessage(ctx interface{}, message interface{}) *MessageRepository_AddMessage_Call {
+	return &MessageRepository_AddMessage_Call{Call: _e.mock.On("AddMessage", ctx, message)}
 }
 
-func (_c *MessageRepository_AddMessage_Call) Run(run func(message models.Message)) *MessageRepository_AddMessage_Call {
+func (_c *MessageRepository_AddMessage_Call) Run(run func(ctx context.Context, message models.Message)) *MessageRepository_AddMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(models.Message))
+		run(args[0].(context.Context), args[1].(models.Message))
 	})
 	return _c
 }
@@ -71,14 +78,445 @@ func (_c *MessageRepository_AddMessage_Call) Return(_a0 int, _a1 error) *Message
 	return _c
 }
 
-func (_c *MessageRepository_AddMessage_Call) RunAndReturn(run func(models.Message) (int, error)) *MessageRepository_AddMessage_Call {
+func (_c *MessageRepository_AddMessage_Call) RunAndReturn(run func(context.Context, models.Message) (int, error)) *MessageRepository_AddMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelMessage provides a mock function with given fields: ctx, id
+func (_m *MessageRepository) CancelMessage(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_CancelMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelMessage'
+type MessageRepository_CancelMessage_Call struct {
+	*mock.Call
+}
+
+// CancelMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageRepository_Expecter) CancelMessage(ctx interface{}, id interface{}) *MessageRepository_CancelMessage_Call {
+	return &MessageRepository_CancelMessage_Call{Call: _e.mock.On("CancelMessage", ctx, id)}
+}
+
+func (_c *MessageRepository_CancelMessage_Call) Run(run func(ctx context.Context, id int)) *MessageRepository_CancelMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_CancelMessage_Call) Return(_a0 error) *MessageRepository_CancelMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_CancelMessage_Call) RunAndReturn(run func(context.Context, int) error) *MessageRepository_CancelMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClaimUnsentMessages provides a mock function with given fields: ctx, limit, reserved
+func (_m *MessageRepository) ClaimUnsentMessages(ctx context.Context, limit int, reserved int) ([]models.Message, error) {
+	ret := _m.Called(ctx, limit, reserved)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimUnsentMessages")
+	}
+
+	var r0 []models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]models.Message, error)); ok {
+		return rf(ctx, limit, reserved)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []models.Message); ok {
+		r0 = rf(ctx, limit, reserved)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, limit, reserved)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageRepository_ClaimUnsentMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimUnsentMessages'
+type MessageRepository_ClaimUnsentMessages_Call struct {
+	*mock.Call
+}
+
+// ClaimUnsentMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - limit int
+//   - reserved int
+func (_e *MessageRepository_Expecter) ClaimUnsentMessages(ctx interface{}, limit interface{}, reserved interface{}) *MessageRepository_ClaimUnsentMessages_Call {
+	return &MessageRepository_ClaimUnsentMessages_Call{Call: _e.mock.On("ClaimUnsentMessages", ctx, limit, reserved)}
+}
+
+func (_c *MessageRepository_ClaimUnsentMessages_Call) Run(run func(ctx context.Context, limit int, reserved int)) *MessageRepository_ClaimUnsentMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_ClaimUnsentMessages_Call) Return(_a0 []models.Message, _a1 error) *MessageRepository_ClaimUnsentMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageRepository_ClaimUnsentMessages_Call) RunAndReturn(run func(context.Context, int, int) ([]models.Message, error)) *MessageRepository_ClaimUnsentMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountUnsentMessages provides a mock function with given fields: ctx
+func (_m *MessageRepository) CountUnsentMessages(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUnsentMessages")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageRepository_CountUnsentMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountUnsentMessages'
+type MessageRepository_CountUnsentMessages_Call struct {
+	*mock.Call
+}
+
+// CountUnsentMessages is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MessageRepository_Expecter) CountUnsentMessages(ctx interface{}) *MessageRepository_CountUnsentMessages_Call {
+	return &MessageRepository_CountUnsentMessages_Call{Call: _e.mock.On("CountUnsentMessages", ctx)}
+}
+
+func (_c *MessageRepository_CountUnsentMessages_Call) Run(run func(ctx context.Context)) *MessageRepository_CountUnsentMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_CountUnsentMessages_Call) Return(_a0 int, _a1 error) *MessageRepository_CountUnsentMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageRepository_CountUnsentMessages_Call) RunAndReturn(run func(context.Context) (int, error)) *MessageRepository_CountUnsentMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeferMessage provides a mock function with given fields: ctx, id, until
+func (_m *MessageRepository) DeferMessage(ctx context.Context, id int, until time.Time) error {
+	ret := _m.Called(ctx, id, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeferMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, id, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_DeferMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeferMessage'
+type MessageRepository_DeferMessage_Call struct {
+	*mock.Call
+}
+
+// DeferMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - until time.Time
+func (_e *MessageRepository_Expecter) DeferMessage(ctx interface{}, id interface{}, until interface{}) *MessageRepository_DeferMessage_Call {
+	return &MessageRepository_DeferMessage_Call{Call: _e.mock.On("DeferMessage", ctx, id, until)}
+}
+
+func (_c *MessageRepository_DeferMessage_Call) Run(run func(ctx context.Context, id int, until time.Time)) *MessageRepository_DeferMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_DeferMessage_Call) Return(_a0 error) *MessageRepository_DeferMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_DeferMessage_Call) RunAndReturn(run func(context.Context, int, time.Time) error) *MessageRepository_DeferMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMessage provides a mock function with given fields: ctx, id
+func (_m *MessageRepository) DeleteMessage(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_DeleteMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessage'
+type MessageRepository_DeleteMessage_Call struct {
+	*mock.Call
+}
+
+// DeleteMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageRepository_Expecter) DeleteMessage(ctx interface{}, id interface{}) *MessageRepository_DeleteMessage_Call {
+	return &MessageRepository_DeleteMessage_Call{Call: _e.mock.On("DeleteMessage", ctx, id)}
+}
+
+func (_c *MessageRepository_DeleteMessage_Call) Run(run func(ctx context.Context, id int)) *MessageRepository_DeleteMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_DeleteMessage_Call) Return(_a0 error) *MessageRepository_DeleteMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_DeleteMessage_Call) RunAndReturn(run func(context.Context, int) error) *MessageRepository_DeleteMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetSentMessages provides a mock function with given fields: page, limit
-func (_m *MessageRepository) GetSentMessages(page int, limit int) ([]models.Message, int, error) {
-	ret := _m.Called(page, limit)
+// GetMessage provides a mock function with given fields: ctx, id
+func (_m *MessageRepository) GetMessage(ctx context.Context, id int) (models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageRepository_GetMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessage'
+type MessageRepository_GetMessage_Call struct {
+	*mock.Call
+}
+
+// GetMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageRepository_Expecter) GetMessage(ctx interface{}, id interface{}) *MessageRepository_GetMessage_Call {
+	return &MessageRepository_GetMessage_Call{Call: _e.mock.On("GetMessage", ctx, id)}
+}
+
+func (_c *MessageRepository_GetMessage_Call) Run(run func(ctx context.Context, id int)) *MessageRepository_GetMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_GetMessage_Call) Return(_a0 models.Message, _a1 error) *MessageRepository_GetMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageRepository_GetMessage_Call) RunAndReturn(run func(context.Context, int) (models.Message, error)) *MessageRepository_GetMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessageByExternalID provides a mock function with given fields: ctx, externalID
+func (_m *MessageRepository) GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error) {
+	ret := _m.Called(ctx, externalID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageByExternalID")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Message, error)); ok {
+		return rf(ctx, externalID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Message); ok {
+		r0 = rf(ctx, externalID)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, externalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageRepository_GetMessageByExternalID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageByExternalID'
+type MessageRepository_GetMessageByExternalID_Call struct {
+	*mock.Call
+}
+
+// GetMessageByExternalID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - externalID string
+func (_e *MessageRepository_Expecter) GetMessageByExternalID(ctx interface{}, externalID interface{}) *MessageRepository_GetMessageByExternalID_Call {
+	return &MessageRepository_GetMessageByExternalID_Call{Call: _e.mock.On("GetMessageByExternalID", ctx, externalID)}
+}
+
+func (_c *MessageRepository_GetMessageByExternalID_Call) Run(run func(ctx context.Context, externalID string)) *MessageRepository_GetMessageByExternalID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_GetMessageByExternalID_Call) Return(_a0 models.Message, _a1 error) *MessageRepository_GetMessageByExternalID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageRepository_GetMessageByExternalID_Call) RunAndReturn(run func(context.Context, string) (models.Message, error)) *MessageRepository_GetMessageByExternalID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResends provides a mock function with given fields: ctx, parentID
+func (_m *MessageRepository) GetResends(ctx context.Context, parentID int) ([]models.Message, error) {
+	ret := _m.Called(ctx, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResends")
+	}
+
+	var r0 []models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.Message, error)); ok {
+		return rf(ctx, parentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.Message); ok {
+		r0 = rf(ctx, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageRepository_GetResends_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResends'
+type MessageRepository_GetResends_Call struct {
+	*mock.Call
+}
+
+// GetResends is a helper method to define mock.On call
+//   - ctx context.Context
+//   - parentID int
+func (_e *MessageRepository_Expecter) GetResends(ctx interface{}, parentID interface{}) *MessageRepository_GetResends_Call {
+	return &MessageRepository_GetResends_Call{Call: _e.mock.On("GetResends", ctx, parentID)}
+}
+
+func (_c *MessageRepository_GetResends_Call) Run(run func(ctx context.Context, parentID int)) *MessageRepository_GetResends_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_GetResends_Call) Return(_a0 []models.Message, _a1 error) *MessageRepository_GetResends_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageRepository_GetResends_Call) RunAndReturn(run func(context.Context, int) ([]models.Message, error)) *MessageRepository_GetResends_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSentMessages provides a mock function with given fields: ctx, page, limit
+func (_m *MessageRepository) GetSentMessages(ctx context.Context, page int, limit int) ([]models.Message, int, error) {
+	ret := _m.Called(ctx, page, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetSentMessages")
@@ -87,25 +525,25 @@ func (_m *MessageRepository) GetSentMessages(page int, limit int) ([]models.Mess
 	var r0 []models.Message
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(int, int) ([]models.Message, int, error)); ok {
-		return rf(page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]models.Message, int, error)); ok {
+		return rf(ctx, page, limit)
 	}
-	if rf, ok := ret.Get(0).(func(int, int) []models.Message); ok {
-		r0 = rf(page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []models.Message); ok {
+		r0 = rf(ctx, page, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Message)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, int) int); ok {
-		r1 = rf(page, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = rf(ctx, page, limit)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(int, int) error); ok {
-		r2 = rf(page, limit)
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, page, limit)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -119,15 +557,16 @@ type MessageRepository_GetSentMessages_Call struct {
 }
 
 // GetSentMessages is a helper method to define mock.On call
+//   - ctx context.Context
 //   - page int
 //   - limit int
-func (_e *MessageRepository_Expecter) GetSentMessages(page interface{}, limit interface{}) *MessageRepository_GetSentMessages_Call {
-	return &MessageRepository_GetSentMessages_Call{Call: _e.mock.On("GetSentMessages", page, limit)}
+func (_e *MessageRepository_Expecter) GetSentMessages(ctx interface{}, page interface{}, limit interface{}) *MessageRepository_GetSentMessages_Call {
+	return &MessageRepository_GetSentMessages_Call{Call: _e.mock.On("GetSentMessages", ctx, page, limit)}
 }
 
-func (_c *MessageRepository_GetSentMessages_Call) Run(run func(page int, limit int)) *MessageRepository_GetSentMessages_Call {
+func (_c *MessageRepository_GetSentMessages_Call) Run(run func(ctx context.Context, page int, limit int)) *MessageRepository_GetSentMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int), args[1].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
 	})
 	return _c
 }
@@ -137,34 +576,32 @@ func (_c *MessageRepository_GetSentMessages_Call) Return(_a0 []models.Message, _
 	return _c
 }
 
-func (_c *MessageRepository_GetSentMessages_Call) RunAndReturn(run func(int, int) ([]models.Message, int, error)) *MessageRepository_GetSentMessages_Call {
+func (_c *MessageRepository_GetSentMessages_Call) RunAndReturn(run func(context.Context, int, int) ([]models.Message, int, error)) *MessageRepository_GetSentMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUnsentMessages provides a mock function with given fields: limit
-func (_m *MessageRepository) GetUnsentMessages(limit int) ([]models.Message, error) {
-	ret := _m.Called(limit)
+// ListMessages provides a mock function with given fields: ctx, filter
+func (_m *MessageRepository) ListMessages(ctx context.Context, filter repository.MessageFilter) (repository.MessagePage, error) {
+	ret := _m.Called(ctx, filter)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUnsentMessages")
+		panic("no return value specified for ListMessages")
 	}
 
-	var r0 []models.Message
+	var r0 repository.MessagePage
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) ([]models.Message, error)); ok {
-		return rf(limit)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MessageFilter) (repository.MessagePage, error)); ok {
+		return rf(ctx, filter)
 	}
-	if rf, ok := ret.Get(0).(func(int) []models.Message); ok {
-		r0 = rf(limit)
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MessageFilter) repository.MessagePage); ok {
+		r0 = rf(ctx, filter)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Message)
-		}
+		r0 = ret.Get(0).(repository.MessagePage)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(limit)
+	if rf, ok := ret.Get(1).(func(context.Context, repository.MessageFilter) error); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -172,45 +609,46 @@ func (_m *MessageRepository) GetUnsentMessages(limit int) ([]models.Message, err
 	return r0, r1
 }
 
-// MessageRepository_GetUnsentMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUnsentMessages'
-type MessageRepository_GetUnsentMessages_Call struct {
+// MessageRepository_ListMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessages'
+type MessageRepository_ListMessages_Call struct {
 	*mock.Call
 }
 
-// GetUnsentMessages is a helper method to define mock.On call
-//   - limit int
-func (_e *MessageRepository_Expecter) GetUnsentMessages(limit interface{}) *MessageRepository_GetUnsentMessages_Call {
-	return &MessageRepository_GetUnsentMessages_Call{Call: _e.mock.On("GetUnsentMessages", limit)}
+// ListMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter repository.MessageFilter
+func (_e *MessageRepository_Expecter) ListMessages(ctx interface{}, filter interface{}) *MessageRepository_ListMessages_Call {
+	return &MessageRepository_ListMessages_Call{Call: _e.mock.On("ListMessages", ctx, filter)}
 }
 
-func (_c *MessageRepository_GetUnsentMessages_Call) Run(run func(limit int)) *MessageRepository_GetUnsentMessages_Call {
+func (_c *MessageRepository_ListMessages_Call) Run(run func(ctx context.Context, filter repository.MessageFilter)) *MessageRepository_ListMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int))
+		run(args[0].(context.Context), args[1].(repository.MessageFilter))
 	})
 	return _c
 }
 
-func (_c *MessageRepository_GetUnsentMessages_Call) Return(_a0 []models.Message, _a1 error) *MessageRepository_GetUnsentMessages_Call {
+func (_c *MessageRepository_ListMessages_Call) Return(_a0 repository.MessagePage, _a1 error) *MessageRepository_ListMessages_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MessageRepository_GetUnsentMessages_Call) RunAndReturn(run func(int) ([]models.Message, error)) *MessageRepository_GetUnsentMessages_Call {
+func (_c *MessageRepository_ListMessages_Call) RunAndReturn(run func(context.Context, repository.MessageFilter) (repository.MessagePage, error)) *MessageRepository_ListMessages_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// MarkMessageAsSent provides a mock function with given fields: id, externalMsgID
-func (_m *MessageRepository) MarkMessageAsSent(id int, externalMsgID string) error {
-	ret := _m.Called(id, externalMsgID)
+// MarkMessageAsSent provides a mock function with given fields: ctx, id, externalMsgID
+func (_m *MessageRepository) MarkMessageAsSent(ctx context.Context, id int, externalMsgID string) error {
+	ret := _m.Called(ctx, id, externalMsgID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for MarkMessageAsSent")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int, string) error); ok {
-		r0 = rf(id, externalMsgID)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, externalMsgID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -224,15 +662,16 @@ type MessageRepository_MarkMessageAsSent_Call struct {
 }
 
 // MarkMessageAsSent is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id int
 //   - externalMsgID string
-func (_e *MessageRepository_Expecter) MarkMessageAsSent(id interface{}, externalMsgID interface{}) *MessageRepository_MarkMessageAsSent_Call {
-	return &MessageRepository_MarkMessageAsSent_Call{Call: _e.mock.On("MarkMessageAsSent", id, externalMsgID)}
+func (_e *MessageRepository_Expecter) MarkMessageAsSent(ctx interface{}, id interface{}, externalMsgID interface{}) *MessageRepository_MarkMessageAsSent_Call {
+	return &MessageRepository_MarkMessageAsSent_Call{Call: _e.mock.On("MarkMessageAsSent", ctx, id, externalMsgID)}
 }
 
-func (_c *MessageRepository_MarkMessageAsSent_Call) Run(run func(id int, externalMsgID string)) *MessageRepository_MarkMessageAsSent_Call {
+func (_c *MessageRepository_MarkMessageAsSent_Call) Run(run func(ctx context.Context, id int, externalMsgID string)) *MessageRepository_MarkMessageAsSent_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int), args[1].(string))
+		run(args[0].(context.Context), args[1].(int), args[2].(string))
 	})
 	return _c
 }
@@ -242,7 +681,151 @@ func (_c *MessageRepository_MarkMessageAsSent_Call) Return(_a0 error) *MessageRe
 	return _c
 }
 
-func (_c *MessageRepository_MarkMessageAsSent_Call) RunAndReturn(run func(int, string) error) *MessageRepository_MarkMessageAsSent_Call {
+func (_c *MessageRepository_MarkMessageAsSent_Call) RunAndReturn(run func(context.Context, int, string) error) *MessageRepository_MarkMessageAsSent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordSendFailure provides a mock function with given fields: ctx, id, reason, permanent
+func (_m *MessageRepository) RecordSendFailure(ctx context.Context, id int, reason string, permanent bool) error {
+	ret := _m.Called(ctx, id, reason, permanent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordSendFailure")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, bool) error); ok {
+		r0 = rf(ctx, id, reason, permanent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_RecordSendFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordSendFailure'
+type MessageRepository_RecordSendFailure_Call struct {
+	*mock.Call
+}
+
+// RecordSendFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - reason string
+//   - permanent bool
+func (_e *MessageRepository_Expecter) RecordSendFailure(ctx interface{}, id interface{}, reason interface{}, permanent interface{}) *MessageRepository_RecordSendFailure_Call {
+	return &MessageRepository_RecordSendFailure_Call{Call: _e.mock.On("RecordSendFailure", ctx, id, reason, permanent)}
+}
+
+func (_c *MessageRepository_RecordSendFailure_Call) Run(run func(ctx context.Context, id int, reason string, permanent bool)) *MessageRepository_RecordSendFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_RecordSendFailure_Call) Return(_a0 error) *MessageRepository_RecordSendFailure_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_RecordSendFailure_Call) RunAndReturn(run func(context.Context, int, string, bool) error) *MessageRepository_RecordSendFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReleaseMessages provides a mock function with given fields: ctx, ids
+func (_m *MessageRepository) ReleaseMessages(ctx context.Context, ids []int) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReleaseMessages")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_ReleaseMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReleaseMessages'
+type MessageRepository_ReleaseMessages_Call struct {
+	*mock.Call
+}
+
+// ReleaseMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []int
+func (_e *MessageRepository_Expecter) ReleaseMessages(ctx interface{}, ids interface{}) *MessageRepository_ReleaseMessages_Call {
+	return &MessageRepository_ReleaseMessages_Call{Call: _e.mock.On("ReleaseMessages", ctx, ids)}
+}
+
+func (_c *MessageRepository_ReleaseMessages_Call) Run(run func(ctx context.Context, ids []int)) *MessageRepository_ReleaseMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_ReleaseMessages_Call) Return(_a0 error) *MessageRepository_ReleaseMessages_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_ReleaseMessages_Call) RunAndReturn(run func(context.Context, []int) error) *MessageRepository_ReleaseMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateMessage provides a mock function with given fields: ctx, id, update
+func (_m *MessageRepository) UpdateMessage(ctx context.Context, id int, update repository.MessageUpdate) error {
+	ret := _m.Called(ctx, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, repository.MessageUpdate) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageRepository_UpdateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessage'
+type MessageRepository_UpdateMessage_Call struct {
+	*mock.Call
+}
+
+// UpdateMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - update repository.MessageUpdate
+func (_e *MessageRepository_Expecter) UpdateMessage(ctx interface{}, id interface{}, update interface{}) *MessageRepository_UpdateMessage_Call {
+	return &MessageRepository_UpdateMessage_Call{Call: _e.mock.On("UpdateMessage", ctx, id, update)}
+}
+
+func (_c *MessageRepository_UpdateMessage_Call) Run(run func(ctx context.Context, id int, update repository.MessageUpdate)) *MessageRepository_UpdateMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(repository.MessageUpdate))
+	})
+	return _c
+}
+
+func (_c *MessageRepository_UpdateMessage_Call) Return(_a0 error) *MessageRepository_UpdateMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageRepository_UpdateMessage_Call) RunAndReturn(run func(context.Context, int, repository.MessageUpdate) error) *MessageRepository_UpdateMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/alper.meric/messaging-system/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repository "github.com/alper.meric/messaging-system/repository"
 )
 
 // MessageServiceInterface is an autogenerated mock type for the MessageServiceInterface type
@@ -20,9 +24,388 @@ func (_m *MessageServiceInterface) EXPECT() *MessageServiceInterface_Expecter {
 	return &MessageServiceInterface_Expecter{mock: &_m.Mock}
 }
 
-// GetSentMessages provides a mock function with given fields: page, limit
-func (_m *MessageServiceInterface) GetSentMessages(page int, limit int) ([]models.Message, int, error) {
-	ret := _m.Called(page, limit)
+// CancelMessage provides a mock function with given fields: ctx, id
+func (_m *MessageServiceInterface) CancelMessage(ctx context.Context, id int) (models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_CancelMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelMessage'
+type MessageServiceInterface_CancelMessage_Call struct {
+	*mock.Call
+}
+
+// CancelMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageServiceInterface_Expecter) CancelMessage(ctx interface{}, id interface{}) *MessageServiceInterface_CancelMessage_Call {
+	return &MessageServiceInterface_CancelMessage_Call{Call: _e.mock.On("CancelMessage", ctx, id)}
+}
+
+func (_c *MessageServiceInterface_CancelMessage_Call) Run(run func(ctx context.Context, id int)) *MessageServiceInterface_CancelMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_CancelMessage_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_CancelMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_CancelMessage_Call) RunAndReturn(run func(context.Context, int) (models.Message, error)) *MessageServiceInterface_CancelMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateMessage provides a mock function with given fields: ctx, msg
+func (_m *MessageServiceInterface) CreateMessage(ctx context.Context, msg models.Message) (models.Message, error) {
+	ret := _m.Called(ctx, msg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Message) (models.Message, error)); ok {
+		return rf(ctx, msg)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Message) models.Message); ok {
+		r0 = rf(ctx, msg)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Message) error); ok {
+		r1 = rf(ctx, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_CreateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMessage'
+type MessageServiceInterface_CreateMessage_Call struct {
+	*mock.Call
+}
+
+// CreateMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - msg models.Message
+func (_e *MessageServiceInterface_Expecter) CreateMessage(ctx interface{}, msg interface{}) *MessageServiceInterface_CreateMessage_Call {
+	return &MessageServiceInterface_CreateMessage_Call{Call: _e.mock.On("CreateMessage", ctx, msg)}
+}
+
+func (_c *MessageServiceInterface_CreateMessage_Call) Run(run func(ctx context.Context, msg models.Message)) *MessageServiceInterface_CreateMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Message))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_CreateMessage_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_CreateMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_CreateMessage_Call) RunAndReturn(run func(context.Context, models.Message) (models.Message, error)) *MessageServiceInterface_CreateMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMessage provides a mock function with given fields: ctx, id
+func (_m *MessageServiceInterface) DeleteMessage(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_DeleteMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMessage'
+type MessageServiceInterface_DeleteMessage_Call struct {
+	*mock.Call
+}
+
+// DeleteMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageServiceInterface_Expecter) DeleteMessage(ctx interface{}, id interface{}) *MessageServiceInterface_DeleteMessage_Call {
+	return &MessageServiceInterface_DeleteMessage_Call{Call: _e.mock.On("DeleteMessage", ctx, id)}
+}
+
+func (_c *MessageServiceInterface_DeleteMessage_Call) Run(run func(ctx context.Context, id int)) *MessageServiceInterface_DeleteMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_DeleteMessage_Call) Return(_a0 error) *MessageServiceInterface_DeleteMessage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_DeleteMessage_Call) RunAndReturn(run func(context.Context, int) error) *MessageServiceInterface_DeleteMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Drain provides a mock function with given fields:
+func (_m *MessageServiceInterface) Drain() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Drain")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_Drain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Drain'
+type MessageServiceInterface_Drain_Call struct {
+	*mock.Call
+}
+
+// Drain is a helper method to define mock.On call
+func (_e *MessageServiceInterface_Expecter) Drain() *MessageServiceInterface_Drain_Call {
+	return &MessageServiceInterface_Drain_Call{Call: _e.mock.On("Drain")}
+}
+
+func (_c *MessageServiceInterface_Drain_Call) Run(run func()) *MessageServiceInterface_Drain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_Drain_Call) Return(_a0 error) *MessageServiceInterface_Drain_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_Drain_Call) RunAndReturn(run func() error) *MessageServiceInterface_Drain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessage provides a mock function with given fields: ctx, id
+func (_m *MessageServiceInterface) GetMessage(ctx context.Context, id int) (models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_GetMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessage'
+type MessageServiceInterface_GetMessage_Call struct {
+	*mock.Call
+}
+
+// GetMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageServiceInterface_Expecter) GetMessage(ctx interface{}, id interface{}) *MessageServiceInterface_GetMessage_Call {
+	return &MessageServiceInterface_GetMessage_Call{Call: _e.mock.On("GetMessage", ctx, id)}
+}
+
+func (_c *MessageServiceInterface_GetMessage_Call) Run(run func(ctx context.Context, id int)) *MessageServiceInterface_GetMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetMessage_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_GetMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetMessage_Call) RunAndReturn(run func(context.Context, int) (models.Message, error)) *MessageServiceInterface_GetMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMessageByExternalID provides a mock function with given fields: ctx, externalID
+func (_m *MessageServiceInterface) GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error) {
+	ret := _m.Called(ctx, externalID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageByExternalID")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Message, error)); ok {
+		return rf(ctx, externalID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Message); ok {
+		r0 = rf(ctx, externalID)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, externalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_GetMessageByExternalID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMessageByExternalID'
+type MessageServiceInterface_GetMessageByExternalID_Call struct {
+	*mock.Call
+}
+
+// GetMessageByExternalID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - externalID string
+func (_e *MessageServiceInterface_Expecter) GetMessageByExternalID(ctx interface{}, externalID interface{}) *MessageServiceInterface_GetMessageByExternalID_Call {
+	return &MessageServiceInterface_GetMessageByExternalID_Call{Call: _e.mock.On("GetMessageByExternalID", ctx, externalID)}
+}
+
+func (_c *MessageServiceInterface_GetMessageByExternalID_Call) Run(run func(ctx context.Context, externalID string)) *MessageServiceInterface_GetMessageByExternalID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetMessageByExternalID_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_GetMessageByExternalID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetMessageByExternalID_Call) RunAndReturn(run func(context.Context, string) (models.Message, error)) *MessageServiceInterface_GetMessageByExternalID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetResends provides a mock function with given fields: ctx, id
+func (_m *MessageServiceInterface) GetResends(ctx context.Context, id int) ([]models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetResends")
+	}
+
+	var r0 []models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_GetResends_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetResends'
+type MessageServiceInterface_GetResends_Call struct {
+	*mock.Call
+}
+
+// GetResends is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageServiceInterface_Expecter) GetResends(ctx interface{}, id interface{}) *MessageServiceInterface_GetResends_Call {
+	return &MessageServiceInterface_GetResends_Call{Call: _e.mock.On("GetResends", ctx, id)}
+}
+
+func (_c *MessageServiceInterface_GetResends_Call) Run(run func(ctx context.Context, id int)) *MessageServiceInterface_GetResends_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetResends_Call) Return(_a0 []models.Message, _a1 error) *MessageServiceInterface_GetResends_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetResends_Call) RunAndReturn(run func(context.Context, int) ([]models.Message, error)) *MessageServiceInterface_GetResends_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSentMessages provides a mock function with given fields: ctx, page, limit
+func (_m *MessageServiceInterface) GetSentMessages(ctx context.Context, page int, limit int) ([]models.Message, int, error) {
+	ret := _m.Called(ctx, page, limit)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetSentMessages")
@@ -31,25 +414,25 @@ func (_m *MessageServiceInterface) GetSentMessages(page int, limit int) ([]model
 	var r0 []models.Message
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(int, int) ([]models.Message, int, error)); ok {
-		return rf(page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]models.Message, int, error)); ok {
+		return rf(ctx, page, limit)
 	}
-	if rf, ok := ret.Get(0).(func(int, int) []models.Message); ok {
-		r0 = rf(page, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []models.Message); ok {
+		r0 = rf(ctx, page, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Message)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int, int) int); ok {
-		r1 = rf(page, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) int); ok {
+		r1 = rf(ctx, page, limit)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(int, int) error); ok {
-		r2 = rf(page, limit)
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, page, limit)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -63,15 +446,16 @@ type MessageServiceInterface_GetSentMessages_Call struct {
 }
 
 // GetSentMessages is a helper method to define mock.On call
+//   - ctx context.Context
 //   - page int
 //   - limit int
-func (_e *MessageServiceInterface_Expecter) GetSentMessages(page interface{}, limit interface{}) *MessageServiceInterface_GetSentMessages_Call {
-	return &MessageServiceInterface_GetSentMessages_Call{Call: _e.mock.On("GetSentMessages", page, limit)}
+func (_e *MessageServiceInterface_Expecter) GetSentMessages(ctx interface{}, page interface{}, limit interface{}) *MessageServiceInterface_GetSentMessages_Call {
+	return &MessageServiceInterface_GetSentMessages_Call{Call: _e.mock.On("GetSentMessages", ctx, page, limit)}
 }
 
-func (_c *MessageServiceInterface_GetSentMessages_Call) Run(run func(page int, limit int)) *MessageServiceInterface_GetSentMessages_Call {
+func (_c *MessageServiceInterface_GetSentMessages_Call) Run(run func(ctx context.Context, page int, limit int)) *MessageServiceInterface_GetSentMessages_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(int), args[1].(int))
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
 	})
 	return _c
 }
@@ -81,7 +465,396 @@ func (_c *MessageServiceInterface_GetSentMessages_Call) Return(_a0 []models.Mess
 	return _c
 }
 
-func (_c *MessageServiceInterface_GetSentMessages_Call) RunAndReturn(run func(int, int) ([]models.Message, int, error)) *MessageServiceInterface_GetSentMessages_Call {
+func (_c *MessageServiceInterface_GetSentMessages_Call) RunAndReturn(run func(context.Context, int, int) ([]models.Message, int, error)) *MessageServiceInterface_GetSentMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetStatus provides a mock function with given fields: ctx
+func (_m *MessageServiceInterface) GetStatus(ctx context.Context) models.ServiceStatus {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStatus")
+	}
+
+	var r0 models.ServiceStatus
+	if rf, ok := ret.Get(0).(func(context.Context) models.ServiceStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(models.ServiceStatus)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_GetStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetStatus'
+type MessageServiceInterface_GetStatus_Call struct {
+	*mock.Call
+}
+
+// GetStatus is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MessageServiceInterface_Expecter) GetStatus(ctx interface{}) *MessageServiceInterface_GetStatus_Call {
+	return &MessageServiceInterface_GetStatus_Call{Call: _e.mock.On("GetStatus", ctx)}
+}
+
+func (_c *MessageServiceInterface_GetStatus_Call) Run(run func(ctx context.Context)) *MessageServiceInterface_GetStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetStatus_Call) Return(_a0 models.ServiceStatus) *MessageServiceInterface_GetStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_GetStatus_Call) RunAndReturn(run func(context.Context) models.ServiceStatus) *MessageServiceInterface_GetStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMessages provides a mock function with given fields: ctx, filter
+func (_m *MessageServiceInterface) ListMessages(ctx context.Context, filter repository.MessageFilter) (repository.MessagePage, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMessages")
+	}
+
+	var r0 repository.MessagePage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MessageFilter) (repository.MessagePage, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repository.MessageFilter) repository.MessagePage); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(repository.MessagePage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repository.MessageFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_ListMessages_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMessages'
+type MessageServiceInterface_ListMessages_Call struct {
+	*mock.Call
+}
+
+// ListMessages is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter repository.MessageFilter
+func (_e *MessageServiceInterface_Expecter) ListMessages(ctx interface{}, filter interface{}) *MessageServiceInterface_ListMessages_Call {
+	return &MessageServiceInterface_ListMessages_Call{Call: _e.mock.On("ListMessages", ctx, filter)}
+}
+
+func (_c *MessageServiceInterface_ListMessages_Call) Run(run func(ctx context.Context, filter repository.MessageFilter)) *MessageServiceInterface_ListMessages_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(repository.MessageFilter))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_ListMessages_Call) Return(_a0 repository.MessagePage, _a1 error) *MessageServiceInterface_ListMessages_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_ListMessages_Call) RunAndReturn(run func(context.Context, repository.MessageFilter) (repository.MessagePage, error)) *MessageServiceInterface_ListMessages_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Pause provides a mock function with given fields:
+func (_m *MessageServiceInterface) Pause() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pause")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_Pause_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pause'
+type MessageServiceInterface_Pause_Call struct {
+	*mock.Call
+}
+
+// Pause is a helper method to define mock.On call
+func (_e *MessageServiceInterface_Expecter) Pause() *MessageServiceInterface_Pause_Call {
+	return &MessageServiceInterface_Pause_Call{Call: _e.mock.On("Pause")}
+}
+
+func (_c *MessageServiceInterface_Pause_Call) Run(run func()) *MessageServiceInterface_Pause_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_Pause_Call) Return(_a0 error) *MessageServiceInterface_Pause_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_Pause_Call) RunAndReturn(run func() error) *MessageServiceInterface_Pause_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PreviewContent provides a mock function with given fields: content, transliteration
+func (_m *MessageServiceInterface) PreviewContent(content string, transliteration string) models.ContentPreview {
+	ret := _m.Called(content, transliteration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PreviewContent")
+	}
+
+	var r0 models.ContentPreview
+	if rf, ok := ret.Get(0).(func(string, string) models.ContentPreview); ok {
+		r0 = rf(content, transliteration)
+	} else {
+		r0 = ret.Get(0).(models.ContentPreview)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_PreviewContent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PreviewContent'
+type MessageServiceInterface_PreviewContent_Call struct {
+	*mock.Call
+}
+
+// PreviewContent is a helper method to define mock.On call
+//   - content string
+//   - transliteration string
+func (_e *MessageServiceInterface_Expecter) PreviewContent(content interface{}, transliteration interface{}) *MessageServiceInterface_PreviewContent_Call {
+	return &MessageServiceInterface_PreviewContent_Call{Call: _e.mock.On("PreviewContent", content, transliteration)}
+}
+
+func (_c *MessageServiceInterface_PreviewContent_Call) Run(run func(content string, transliteration string)) *MessageServiceInterface_PreviewContent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_PreviewContent_Call) Return(_a0 models.ContentPreview) *MessageServiceInterface_PreviewContent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_PreviewContent_Call) RunAndReturn(run func(string, string) models.ContentPreview) *MessageServiceInterface_PreviewContent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResendMessage provides a mock function with given fields: ctx, id
+func (_m *MessageServiceInterface) ResendMessage(ctx context.Context, id int) (models.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResendMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (models.Message, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) models.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_ResendMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResendMessage'
+type MessageServiceInterface_ResendMessage_Call struct {
+	*mock.Call
+}
+
+// ResendMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MessageServiceInterface_Expecter) ResendMessage(ctx interface{}, id interface{}) *MessageServiceInterface_ResendMessage_Call {
+	return &MessageServiceInterface_ResendMessage_Call{Call: _e.mock.On("ResendMessage", ctx, id)}
+}
+
+func (_c *MessageServiceInterface_ResendMessage_Call) Run(run func(ctx context.Context, id int)) *MessageServiceInterface_ResendMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_ResendMessage_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_ResendMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_ResendMessage_Call) RunAndReturn(run func(context.Context, int) (models.Message, error)) *MessageServiceInterface_ResendMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Resume provides a mock function with given fields:
+func (_m *MessageServiceInterface) Resume() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_Resume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resume'
+type MessageServiceInterface_Resume_Call struct {
+	*mock.Call
+}
+
+// Resume is a helper method to define mock.On call
+func (_e *MessageServiceInterface_Expecter) Resume() *MessageServiceInterface_Resume_Call {
+	return &MessageServiceInterface_Resume_Call{Call: _e.mock.On("Resume")}
+}
+
+func (_c *MessageServiceInterface_Resume_Call) Run(run func()) *MessageServiceInterface_Resume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_Resume_Call) Return(_a0 error) *MessageServiceInterface_Resume_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_Resume_Call) RunAndReturn(run func() error) *MessageServiceInterface_Resume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RunOnce provides a mock function with given fields: ctx
+func (_m *MessageServiceInterface) RunOnce(ctx context.Context) models.RunSummary {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunOnce")
+	}
+
+	var r0 models.RunSummary
+	if rf, ok := ret.Get(0).(func(context.Context) models.RunSummary); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(models.RunSummary)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_RunOnce_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunOnce'
+type MessageServiceInterface_RunOnce_Call struct {
+	*mock.Call
+}
+
+// RunOnce is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MessageServiceInterface_Expecter) RunOnce(ctx interface{}) *MessageServiceInterface_RunOnce_Call {
+	return &MessageServiceInterface_RunOnce_Call{Call: _e.mock.On("RunOnce", ctx)}
+}
+
+func (_c *MessageServiceInterface_RunOnce_Call) Run(run func(ctx context.Context)) *MessageServiceInterface_RunOnce_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_RunOnce_Call) Return(_a0 models.RunSummary) *MessageServiceInterface_RunOnce_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_RunOnce_Call) RunAndReturn(run func(context.Context) models.RunSummary) *MessageServiceInterface_RunOnce_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Shutdown provides a mock function with given fields: ctx
+func (_m *MessageServiceInterface) Shutdown(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Shutdown")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessageServiceInterface_Shutdown_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Shutdown'
+type MessageServiceInterface_Shutdown_Call struct {
+	*mock.Call
+}
+
+// Shutdown is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MessageServiceInterface_Expecter) Shutdown(ctx interface{}) *MessageServiceInterface_Shutdown_Call {
+	return &MessageServiceInterface_Shutdown_Call{Call: _e.mock.On("Shutdown", ctx)}
+}
+
+func (_c *MessageServiceInterface_Shutdown_Call) Run(run func(ctx context.Context)) *MessageServiceInterface_Shutdown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_Shutdown_Call) Return(_a0 error) *MessageServiceInterface_Shutdown_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessageServiceInterface_Shutdown_Call) RunAndReturn(run func(context.Context) error) *MessageServiceInterface_Shutdown_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -176,17 +949,17 @@ func (_c *MessageServiceInterface_Status_Call) RunAndReturn(run func() bool) *Me
 	return _c
 }
 
-// Stop provides a mock function with given fields:
-func (_m *MessageServiceInterface) Stop() error {
-	ret := _m.Called()
+// Stop provides a mock function with given fields: ctx
+func (_m *MessageServiceInterface) Stop(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Stop")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -200,13 +973,14 @@ type MessageServiceInterface_Stop_Call struct {
 }
 
 // Stop is a helper method to define mock.On call
-func (_e *MessageServiceInterface_Expecter) Stop() *MessageServiceInterface_Stop_Call {
-	return &MessageServiceInterface_Stop_Call{Call: _e.mock.On("Stop")}
+//   - ctx context.Context
+func (_e *MessageServiceInterface_Expecter) Stop(ctx interface{}) *MessageServiceInterface_Stop_Call {
+	return &MessageServiceInterface_Stop_Call{Call: _e.mock.On("Stop", ctx)}
 }
 
-func (_c *MessageServiceInterface_Stop_Call) Run(run func()) *MessageServiceInterface_Stop_Call {
+func (_c *MessageServiceInterface_Stop_Call) Run(run func(ctx context.Context)) *MessageServiceInterface_Stop_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
@@ -216,7 +990,65 @@ func (_c *MessageServiceInterface_Stop_Call) Return(_a0 error) *MessageServiceIn
 	return _c
 }
 
-func (_c *MessageServiceInterface_Stop_Call) RunAndReturn(run func() error) *MessageServiceInterface_Stop_Call {
+func (_c *MessageServiceInterface_Stop_Call) RunAndReturn(run func(context.Context) error) *MessageServiceInterface_Stop_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateMessage provides a mock function with given fields: ctx, id, update
+func (_m *MessageServiceInterface) UpdateMessage(ctx context.Context, id int, update repository.MessageUpdate) (models.Message, error) {
+	ret := _m.Called(ctx, id, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMessage")
+	}
+
+	var r0 models.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, repository.MessageUpdate) (models.Message, error)); ok {
+		return rf(ctx, id, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, repository.MessageUpdate) models.Message); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Get(0).(models.Message)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, repository.MessageUpdate) error); ok {
+		r1 = rf(ctx, id, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessageServiceInterface_UpdateMessage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateMessage'
+type MessageServiceInterface_UpdateMessage_Call struct {
+	*mock.Call
+}
+
+// UpdateMessage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - update repository.MessageUpdate
+func (_e *MessageServiceInterface_Expecter) UpdateMessage(ctx interface{}, id interface{}, update interface{}) *MessageServiceInterface_UpdateMessage_Call {
+	return &MessageServiceInterface_UpdateMessage_Call{Call: _e.mock.On("UpdateMessage", ctx, id, update)}
+}
+
+func (_c *MessageServiceInterface_UpdateMessage_Call) Run(run func(ctx context.Context, id int, update repository.MessageUpdate)) *MessageServiceInterface_UpdateMessage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(repository.MessageUpdate))
+	})
+	return _c
+}
+
+func (_c *MessageServiceInterface_UpdateMessage_Call) Return(_a0 models.Message, _a1 error) *MessageServiceInterface_UpdateMessage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessageServiceInterface_UpdateMessage_Call) RunAndReturn(run func(context.Context, int, repository.MessageUpdate) (models.Message, error)) *MessageServiceInterface_UpdateMessage_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,15 +9,69 @@ import (
 
 // Message represents a message in the system
 type Message struct {
-	ID            int            `json:"id" gorm:"primaryKey"`
-	Content       string         `json:"content" gorm:"type:text;not null"`
-	PhoneNumber   string         `json:"phoneNumber" gorm:"type:varchar(20);not null"`
-	IsSent        bool           `json:"isSent" gorm:"default:false"`
-	SentAt        time.Time      `json:"sentAt,omitempty" gorm:"default:null"`
-	ExternalMsgID string         `json:"externalMsgId,omitempty" gorm:"default:null"`
-	CreatedAt     time.Time      `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt     time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+	ID              int            `json:"id" gorm:"primaryKey"`
+	Content         string         `json:"content" gorm:"type:text;not null"`
+	PhoneNumber     string         `json:"phoneNumber" gorm:"type:varchar(20);not null"`
+	IsSent          bool           `json:"isSent" gorm:"default:false"`
+	SentAt          time.Time      `json:"sentAt,omitempty" gorm:"default:null"`
+	ExternalMsgID   string         `json:"externalMsgId,omitempty" gorm:"default:null"`
+	Attempts        int            `json:"attempts" gorm:"not null;default:0"`
+	LastError       string         `json:"lastError,omitempty" gorm:"default:null"`
+	FailedAt        *time.Time     `json:"failedAt,omitempty"`
+	ScheduledAt     *time.Time     `json:"scheduledAt,omitempty"`
+	ClaimedAt       *time.Time     `json:"claimedAt,omitempty"`
+	CancelledAt     *time.Time     `json:"cancelledAt,omitempty"`
+	ParentID        *int           `json:"parentId,omitempty"` // original message of a manual resend
+	Category        string         `json:"category" gorm:"type:varchar(20);not null;default:transactional"`
+	Priority        int            `json:"priority" gorm:"not null;default:0"`
+	CountryCode     string         `json:"countryCode,omitempty" gorm:"type:varchar(2);default:null"`     // ISO 3166-1 alpha-2, detected from the phone number
+	Encoding        string         `json:"encoding,omitempty" gorm:"type:varchar(5);default:null"`        // GSM-7 or UCS-2
+	Segments        int            `json:"segments,omitempty" gorm:"default:null"`                        // concatenated SMS segments of the content as sent
+	Transliteration string         `json:"transliteration,omitempty" gorm:"type:varchar(5);default:null"` // language the content is transliterated in to fit GSM-7, off, or empty for the default
+	CreatedAt       time.Time      `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time      `json:"updatedAt" gorm:"autoUpdateTime"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Message statuses, derived from IsSent, FailedAt and CancelledAt
+const (
+	MessageStatusUnsent    = "unsent" // waiting to be sent, possibly after failed attempts
+	MessageStatusSent      = "sent"
+	MessageStatusFailed    = "failed"    // permanently failed, will not be retried
+	MessageStatusCancelled = "cancelled" // cancelled before it was sent
+)
+
+// Message categories. Transactional messages are always sent, promotional
+// ones are held back during the recipient's quiet hours.
+const (
+	MessageCategoryTransactional = "transactional"
+	MessageCategoryPromotional   = "promotional"
+)
+
+// Message priorities. Higher priorities are sent first, a share of each batch
+// is reserved for high-priority messages so they can't starve the others.
+const (
+	MessagePriorityLow    = -1
+	MessagePriorityNormal = 0
+	MessagePriorityHigh   = 1
+)
+
+// MessageTransliterationOff sends a message's content unchanged, regardless of
+// the configured default transliteration
+const MessageTransliterationOff = "off"
+
+// Status returns the status of the message
+func (m Message) Status() string {
+	switch {
+	case m.IsSent:
+		return MessageStatusSent
+	case m.FailedAt != nil:
+		return MessageStatusFailed
+	case m.CancelledAt != nil:
+		return MessageStatusCancelled
+	default:
+		return MessageStatusUnsent
+	}
 }
 
 // TableName sets the table name for the Message model
@@ -36,18 +91,120 @@ type MessageRequest struct {
 	Content string `json:"content" validate:"required"`
 }
 
-// MessageListResponse represents a paginated list of messages
+// MessageListResponse represents a paginated list of messages. Total and
+// Pages are omitted when not counted, Page when paging with cursors.
 type MessageListResponse struct {
-	Success  bool      `json:"success"`
-	Messages []Message `json:"messages"`
-	Total    int       `json:"total"`
-	Page     int       `json:"page"`
-	Limit    int       `json:"limit"`
-	Pages    int       `json:"pages"`
+	Success        bool      `json:"success"`
+	Messages       []Message `json:"messages"`
+	Total          *int      `json:"total,omitempty"`
+	TotalEstimated bool      `json:"totalEstimated,omitempty"`
+	Page           int       `json:"page,omitempty"`
+	Limit          int       `json:"limit"`
+	Pages          *int      `json:"pages,omitempty"`
+	Next           string    `json:"next,omitempty"`
+	Prev           string    `json:"prev,omitempty"`
+}
+
+// MessageDetailResponse represents a single message together with the
+// manual resends of it, oldest first
+type MessageDetailResponse struct {
+	Success bool      `json:"success"`
+	Message Message   `json:"message"`
+	Resends []Message `json:"resends,omitempty"`
+}
+
+// MessagePreviewRequest holds the content to preview
+type MessagePreviewRequest struct {
+	Content         string `json:"content"`
+	Transliteration string `json:"transliteration,omitempty" example:"tr"`
+}
+
+// ContentPreview describes how message content is sent as SMS. Units are
+// septets for GSM-7, where characters of the extension table take two, and
+// UTF-16 code units for UCS-2.
+type ContentPreview struct {
+	// Transliterated is the content as sent, set when it was transliterated
+	Transliterated   string   `json:"transliterated,omitempty"`
+	Encoding         string   `json:"encoding" enums:"GSM-7,UCS-2"`
+	Characters       int      `json:"characters"`
+	Units            int      `json:"units"`
+	Segments         int      `json:"segments"`
+	UnitsPerSegment  int      `json:"unitsPerSegment"`
+	Remaining        int      `json:"remaining"`                  // units left in the last segment
+	NonGSMCharacters []string `json:"nonGsmCharacters,omitempty"` // characters forcing UCS-2
+	MaxCharacters    int      `json:"maxCharacters"`
+	MaxSegments      int      `json:"maxSegments,omitempty"`
+	Valid            bool     `json:"valid"`
+	Error            string   `json:"error,omitempty"` // why the content would be rejected
 }
 
+// MessagePreviewResponse represents the response of a content preview
+type MessagePreviewResponse struct {
+	Success bool           `json:"success"`
+	Preview ContentPreview `json:"preview"`
+}
+
+// MessageCreateRequest represents a new message. Phone numbers without a
+// country code are read in the configured default region.
+type MessageCreateRequest struct {
+	Content     string     `json:"content"`
+	PhoneNumber string     `json:"phoneNumber"`
+	Category    string     `json:"category,omitempty" enums:"transactional,promotional"`
+	Priority    int        `json:"priority,omitempty" enums:"-1,0,1"`
+	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
+
+	// Transliteration overrides the default transliteration: a language
+	// such as tr, or off
+	Transliteration string `json:"transliteration,omitempty" example:"tr"`
+}
+
+// MessageUpdateRequest represents a change to a queued message. Omitted
+// fields are left as they are; a null scheduledAt removes the schedule.
+type MessageUpdateRequest struct {
+	Content     *string         `json:"content,omitempty"`
+	PhoneNumber *string         `json:"phoneNumber,omitempty"`
+	Category    *string         `json:"category,omitempty" enums:"transactional,promotional"`
+	Priority    *int            `json:"priority,omitempty" enums:"-1,0,1"`
+	ScheduledAt json.RawMessage `json:"scheduledAt,omitempty" swaggertype:"string" format:"date-time"`
+
+	// Transliteration is a language such as tr, off, or empty for the default
+	Transliteration *string `json:"transliteration,omitempty" example:"tr"`
+}
+
+// RunSummary holds message counters for one or more processing runs
+type RunSummary struct {
+	Sent     int `json:"sent"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
+	Deferred int `json:"deferred"` // promotional messages held back for the recipient's quiet hours
+}
+
+// Add accumulates the counters of another summary
+func (r *RunSummary) Add(other RunSummary) {
+	r.Sent += other.Sent
+	r.Failed += other.Failed
+	r.Skipped += other.Skipped
+	r.Deferred += other.Deferred
+}
+
+// Modes of the message service
+const (
+	ServiceModeStopped  = "stopped"
+	ServiceModeRunning  = "running"
+	ServiceModePaused   = "paused"   // the scheduler is alive but skips its runs
+	ServiceModeDraining = "draining" // batches run back to back until the queue is empty
+)
+
 // ServiceStatus represents the status of the message service
 type ServiceStatus struct {
-	IsRunning   bool      `json:"isRunning"`
-	LastRunTime time.Time `json:"lastRunTime,omitempty"`
+	IsRunning      bool       `json:"isRunning"`
+	Mode           string     `json:"mode"`
+	LastRunTime    *time.Time `json:"lastRunTime,omitempty"`
+	LastRunEndTime *time.Time `json:"lastRunEndTime,omitempty"`
+	NextRunTime    *time.Time `json:"nextRunTime,omitempty"`
+	LastRun        RunSummary `json:"lastRun"`
+	Total          RunSummary `json:"total"`
+	QueueDepth     *int       `json:"queueDepth,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastErrorTime  *time.Time `json:"lastErrorTime,omitempty"`
 }
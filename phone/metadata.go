@@ -0,0 +1,159 @@
+package phone
+
+// region holds the numbering plan of a country as far as it is needed to
+// parse and validate numbers
+type region struct {
+	callingCode    string
+	nationalPrefix string // trunk prefix dialled before national numbers, empty when leading zeros belong to the number
+	minLength      int    // length of the national significant number
+	maxLength      int
+}
+
+// regions maps ISO 3166-1 alpha-2 codes to their numbering plans. The lengths
+// are the possible lengths of the national significant number over all
+// number types, so a number of that length may still be unassigned.
+var regions = map[string]region{
+	"AE": {"971", "0", 8, 9},
+	"AF": {"93", "0", 9, 9},
+	"AR": {"54", "0", 10, 11},
+	"AT": {"43", "0", 7, 13},
+	"AU": {"61", "0", 9, 9},
+	"AZ": {"994", "0", 9, 9},
+	"BD": {"880", "0", 10, 10},
+	"BE": {"32", "0", 8, 9},
+	"BG": {"359", "0", 8, 9},
+	"BR": {"55", "0", 10, 11},
+	"CA": {"1", "1", 10, 10},
+	"CH": {"41", "0", 9, 9},
+	"CL": {"56", "", 9, 9},
+	"CN": {"86", "0", 10, 11},
+	"CO": {"57", "", 10, 10},
+	"CZ": {"420", "", 9, 9},
+	"DE": {"49", "0", 6, 13},
+	"DK": {"45", "", 8, 8},
+	"DZ": {"213", "0", 8, 9},
+	"EG": {"20", "0", 8, 10},
+	"ES": {"34", "", 9, 9},
+	"FI": {"358", "0", 5, 12},
+	"FR": {"33", "0", 9, 9},
+	"GB": {"44", "0", 9, 10},
+	"GE": {"995", "0", 9, 9},
+	"GR": {"30", "", 10, 10},
+	"HR": {"385", "0", 8, 9},
+	"HU": {"36", "06", 8, 9},
+	"ID": {"62", "0", 8, 12},
+	"IE": {"353", "0", 7, 9},
+	"IL": {"972", "0", 8, 9},
+	"IN": {"91", "0", 10, 10},
+	"IQ": {"964", "0", 8, 10},
+	"IR": {"98", "0", 10, 10},
+	"IT": {"39", "", 6, 11},
+	"JO": {"962", "0", 8, 9},
+	"JP": {"81", "0", 9, 10},
+	"KE": {"254", "0", 9, 9},
+	"KR": {"82", "0", 8, 10},
+	"KW": {"965", "", 8, 8},
+	"KZ": {"7", "8", 10, 10},
+	"LK": {"94", "0", 9, 9},
+	"MA": {"212", "0", 9, 9},
+	"MX": {"52", "", 10, 10},
+	"MY": {"60", "0", 8, 10},
+	"NG": {"234", "0", 8, 10},
+	"NL": {"31", "0", 9, 9},
+	"NO": {"47", "", 8, 8},
+	"NZ": {"64", "0", 8, 10},
+	"PE": {"51", "0", 8, 9},
+	"PH": {"63", "0", 8, 10},
+	"PK": {"92", "0", 9, 10},
+	"PL": {"48", "", 9, 9},
+	"PT": {"351", "", 9, 9},
+	"QA": {"974", "", 8, 8},
+	"RO": {"40", "0", 9, 9},
+	"RS": {"381", "0", 8, 10},
+	"RU": {"7", "8", 10, 10},
+	"SA": {"966", "0", 9, 9},
+	"SE": {"46", "0", 7, 10},
+	"SG": {"65", "", 8, 8},
+	"SK": {"421", "0", 9, 9},
+	"TH": {"66", "0", 8, 9},
+	"TN": {"216", "", 8, 8},
+	"TR": {"90", "0", 10, 10},
+	"UA": {"380", "0", 9, 9},
+	"US": {"1", "1", 10, 10},
+	"VN": {"84", "0", 9, 10},
+	"ZA": {"27", "0", 9, 9},
+}
+
+// sharedCode describes a calling code shared by several countries. Their
+// numbering plans are alike, the country is told by the leading digits of the
+// national number.
+type sharedCode struct {
+	main string // region of numbers matching none of the prefixes
+	// prefixes maps leading digits to regions; an empty region marks numbers
+	// of countries that aren't supported, which are left unattributed
+	prefixes map[string]string
+}
+
+// sharedCodes lists the calling codes shared by several supported countries
+var sharedCodes = map[string]sharedCode{
+	// North American Numbering Plan, by area code
+	"1": {main: "US", prefixes: prefixes(map[string][]string{
+		"CA": {
+			"204", "226", "236", "249", "250", "257", "263", "289", "306", "343",
+			"354", "365", "367", "368", "382", "387", "403", "416", "418", "428",
+			"431", "437", "438", "450", "460", "468", "474", "506", "514", "519",
+			"548", "579", "581", "584", "587", "600", "604", "613", "622", "639",
+			"647", "672", "683", "705", "709", "742", "753", "778", "780", "782",
+			"807", "819", "825", "867", "873", "879", "902", "905",
+		},
+		// Caribbean countries and US territories with their own country codes
+		"": {
+			"242", "246", "264", "268", "284", "340", "345", "441", "473", "649",
+			"658", "664", "670", "671", "684", "721", "758", "767", "784", "787",
+			"809", "829", "849", "868", "869", "876", "939",
+		},
+	})},
+	// Kazakhstan has the numbers starting with 6 and 7
+	"7": {main: "RU", prefixes: prefixes(map[string][]string{
+		"KZ": {"6", "7"},
+	})},
+}
+
+// prefixes inverts lists of leading digits per region
+func prefixes(byRegion map[string][]string) map[string]string {
+	m := make(map[string]string)
+	for region, digits := range byRegion {
+		for _, d := range digits {
+			m[d] = region
+		}
+	}
+	return m
+}
+
+// attribute returns the region a national number with the calling code
+// belongs to, region itself unless the calling code is shared
+func attribute(callingCode, national, region string) string {
+	shared, ok := sharedCodes[callingCode]
+	if !ok {
+		return region
+	}
+	for n := 1; n <= 3 && n <= len(national); n++ {
+		if r, ok := shared.prefixes[national[:n]]; ok {
+			return r
+		}
+	}
+	return shared.main
+}
+
+// callingCodes maps calling codes to regions, built from regions. Shared
+// calling codes map to their main region, whose numbering plan they share.
+var callingCodes = func() map[string]string {
+	codes := make(map[string]string, len(regions))
+	for code, r := range regions {
+		codes[r.callingCode] = code
+	}
+	for callingCode, shared := range sharedCodes {
+		codes[callingCode] = shared.main
+	}
+	return codes
+}()
@@ -0,0 +1,159 @@
+// Package phone parses phone numbers and normalises them to E.164 using
+// numbering plan metadata bundled with the package, without network access.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidNumber is returned for input that is not a phone number or
+	// can't be a number of its country
+	ErrInvalidNumber = errors.New("invalid phone number")
+
+	// ErrUnknownRegion is returned when a region or calling code is not in the
+	// bundled metadata
+	ErrUnknownRegion = errors.New("unknown region")
+)
+
+// maxDigits is the maximum length of an E.164 number without the +
+const maxDigits = 15
+
+// Number is a parsed phone number
+type Number struct {
+	CallingCode string // e.g. 90
+	National    string // national significant number, without trunk prefix
+	Region      string // ISO 3166-1 alpha-2 code of the country, e.g. TR; empty when it isn't supported
+}
+
+// E164 returns the number in E.164 format, e.g. +905321234567
+func (n Number) E164() string {
+	return "+" + n.CallingCode + n.National
+}
+
+// String returns the number in E.164 format
+func (n Number) String() string {
+	return n.E164()
+}
+
+// SupportedRegion reports whether numbers of the region can be parsed
+func SupportedRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// Parse parses a phone number written in international format, with a
+// leading + or 00, or in the national format of defaultRegion. Spaces,
+// dashes, dots, slashes and parentheses are ignored. defaultRegion may be
+// empty when only international numbers are accepted.
+func Parse(raw, defaultRegion string) (Number, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return Number{}, err
+	}
+
+	if international {
+		return parseInternational(raw, digits)
+	}
+
+	if defaultRegion == "" {
+		return Number{}, fmt.Errorf("%w: %q has no country code", ErrInvalidNumber, raw)
+	}
+	code := strings.ToUpper(defaultRegion)
+	r, ok := regions[code]
+	if !ok {
+		return Number{}, fmt.Errorf("%w: %s", ErrUnknownRegion, defaultRegion)
+	}
+
+	// The country code is often written without the +, as in "90 532 ..."
+	if national := strings.TrimPrefix(digits, r.callingCode); national != digits && !r.possible(digits) && r.possible(r.trimPrefix(national)) {
+		digits = national
+	}
+	return r.number(raw, code, r.trimPrefix(digits))
+}
+
+// Normalise returns the E.164 form of a phone number, see Parse
+func Normalise(raw, defaultRegion string) (string, error) {
+	number, err := Parse(raw, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return number.E164(), nil
+}
+
+// parseInternational parses the digits following an international prefix
+func parseInternational(raw, digits string) (Number, error) {
+	// Calling codes are prefix free and at most three digits long
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		code, ok := callingCodes[digits[:n]]
+		if !ok {
+			continue
+		}
+		r := regions[code]
+		// A trunk prefix after the country code, as in +90 (0)532 ..., is dropped
+		return r.number(raw, code, r.trimPrefix(digits[n:]))
+	}
+	return Number{}, fmt.Errorf("%w: calling code of %q", ErrUnknownRegion, raw)
+}
+
+// clean strips separators from a number and reports whether it starts with an
+// international prefix, which is removed as well
+func clean(raw string) (string, bool, error) {
+	number := strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(number, "+"):
+		number, international = number[1:], true
+	case strings.HasPrefix(number, "00"):
+		number, international = number[2:], true
+	}
+
+	var digits strings.Builder
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ', r == '-', r == '.', r == '/', r == '(', r == ')':
+		default:
+			return "", false, fmt.Errorf("%w: unexpected %q in %q", ErrInvalidNumber, r, raw)
+		}
+	}
+	if digits.Len() == 0 {
+		return "", false, fmt.Errorf("%w: %q has no digits", ErrInvalidNumber, raw)
+	}
+	return digits.String(), international, nil
+}
+
+// trimPrefix removes the trunk prefix from a national number when what
+// remains is a possible number. Prefixes other than 0 may also be the first
+// digit of a number, they are only removed when the number is too long.
+func (r region) trimPrefix(digits string) string {
+	national, ok := strings.CutPrefix(digits, r.nationalPrefix)
+	if r.nationalPrefix == "" || !ok || !r.possible(national) {
+		return digits
+	}
+	if r.nationalPrefix[0] != '0' && r.possible(digits) {
+		return digits
+	}
+	return national
+}
+
+// possible reports whether a national significant number has a possible length
+func (r region) possible(national string) bool {
+	return len(national) >= r.minLength && len(national) <= r.maxLength
+}
+
+// number validates a national significant number of the region
+func (r region) number(raw, code, national string) (Number, error) {
+	if !r.possible(national) || len(r.callingCode)+len(national) > maxDigits {
+		return Number{}, fmt.Errorf("%w: %q has the wrong length for %s", ErrInvalidNumber, raw, code)
+	}
+	// Where a trunk prefix is dialled, national numbers don't start with 0;
+	// in the North American plan they start with 2-9
+	if (r.nationalPrefix != "" && national[0] == '0') || (r.callingCode == "1" && national[0] == '1') {
+		return Number{}, fmt.Errorf("%w: %q is not a number of %s", ErrInvalidNumber, raw, code)
+	}
+	return Number{CallingCode: r.callingCode, National: national, Region: attribute(r.callingCode, national, code)}, nil
+}
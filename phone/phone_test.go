@@ -0,0 +1,116 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw           string
+		defaultRegion string
+		e164          string
+		region        string
+	}{
+		// Aynı numaranın farklı yazımları
+		{"+905321234567", "", "+905321234567", "TR"},
+		{"0090 532 123 45 67", "", "+905321234567", "TR"},
+		{"0532 123 45 67", "TR", "+905321234567", "TR"},
+		{"532-123-45-67", "tr", "+905321234567", "TR"},
+		{"90 532 123 45 67", "TR", "+905321234567", "TR"},
+		{"(0532) 123.45.67", "TR", "+905321234567", "TR"},
+		{"+90 (0)532 123 45 67", "", "+905321234567", "TR"},
+		// Uluslararası numaralar varsayılan bölgeden bağımsızdır
+		{"+44 20 7946 0958", "TR", "+442079460958", "GB"},
+		{"020 7946 0958", "GB", "+442079460958", "GB"},
+		{"030 1234567", "DE", "+49301234567", "DE"},
+		{"+49 (0)30 1234567", "", "+49301234567", "DE"},
+		{"(202) 555-0123", "US", "+12025550123", "US"},
+		{"1 202 555 0123", "US", "+12025550123", "US"},
+		{"8 812 123-45-67", "RU", "+78121234567", "RU"},
+		{"+7 812 123 45 67", "", "+78121234567", "RU"},
+		// İtalya'da baştaki sıfır numaraya aittir
+		{"06 1234 5678", "IT", "+390612345678", "IT"},
+		{"+39 06 1234 5678", "", "+390612345678", "IT"},
+		{"+971 50 123 4567", "", "+971501234567", "AE"},
+		// Ortak ülke kodlarında ülke numaranın başından anlaşılır
+		{"+1 416 555 0123", "", "+14165550123", "CA"},
+		{"(604) 555-0123", "US", "+16045550123", "CA"},
+		{"+7 701 123 45 67", "", "+77011234567", "KZ"},
+		{"8 727 123 45 67", "RU", "+77271234567", "KZ"},
+		{"8 495 123 45 67", "KZ", "+74951234567", "RU"},
+		// Desteklenmeyen Karayip ülkelerinin numaraları bir ülkeye atanmaz
+		{"+1 876 555 0123", "", "+18765550123", ""},
+	}
+	for _, tt := range tests {
+		number, err := Parse(tt.raw, tt.defaultRegion)
+		if assert.NoError(t, err, tt.raw) {
+			assert.Equal(t, tt.e164, number.E164(), tt.raw)
+			assert.Equal(t, tt.region, number.Region, tt.raw)
+		}
+	}
+}
+
+func TestParseRejectsImpossibleNumbers(t *testing.T) {
+	tests := []struct {
+		raw           string
+		defaultRegion string
+	}{
+		{"", "TR"},
+		{"+", ""},
+		{"+90abc", ""},
+		{"0532 123 45 6x", "TR"},
+		{"+90123456789", ""},      // bir hane eksik
+		{"+9053212345678", ""},    // bir hane fazla
+		{"+90 0123 456 78 9", ""}, // ulusal numara sıfırla başlamaz
+		{"+1 123 555 0123", ""},   // alan kodu 1 ile başlamaz
+		{"05321234567", ""},       // ülke kodu ve varsayılan bölge yok
+		{"+999123456789", ""},     // bilinmeyen ülke kodu
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.raw, tt.defaultRegion)
+		assert.Error(t, err, tt.raw)
+	}
+
+	_, err := Parse("+90123456789", "")
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+	_, err = Parse("05321234567", "XX")
+	assert.ErrorIs(t, err, ErrUnknownRegion)
+	_, err = Parse("+999123456789", "")
+	assert.ErrorIs(t, err, ErrUnknownRegion)
+}
+
+func TestNormalise(t *testing.T) {
+	normalised, err := Normalise("0532 123 45 67", "TR")
+	assert.NoError(t, err)
+	assert.Equal(t, "+905321234567", normalised)
+
+	_, err = Normalise("123", "TR")
+	assert.ErrorIs(t, err, ErrInvalidNumber)
+}
+
+func TestSupportedRegion(t *testing.T) {
+	assert.True(t, SupportedRegion("TR"))
+	assert.True(t, SupportedRegion("gb"))
+	assert.False(t, SupportedRegion("XX"))
+	assert.False(t, SupportedRegion(""))
+}
+
+func TestMetadata(t *testing.T) {
+	// Her ülke kodu bir bölgeye çözülmeli ve numaralar E.164 sınırına sığmalı
+	for code, r := range regions {
+		assert.Contains(t, callingCodes, r.callingCode, code)
+		assert.LessOrEqual(t, r.minLength, r.maxLength, code)
+		assert.LessOrEqual(t, len(r.callingCode)+r.maxLength, maxDigits, code)
+	}
+	// Ortak ülke kodlarını paylaşan bölgeler aynı numaralandırma planını kullanmalı
+	for callingCode, shared := range sharedCodes {
+		assert.Equal(t, callingCode, regions[shared.main].callingCode, shared.main)
+		for digits, code := range shared.prefixes {
+			if code != "" {
+				assert.Equal(t, regions[shared.main], regions[code], digits)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package recipient
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // for images without a timezone database, like alpine
+
+	"github.com/alper.meric/messaging-system/schedule"
+)
+
+// QuietHours are the hours of the night, in the recipient's timezone, in
+// which promotional messages are not sent
+type QuietHours struct {
+	allowed  schedule.Window
+	fallback *time.Location
+}
+
+// NewQuietHours creates quiet hours from start to end as HH:MM, e.g. 21:00
+// to 09:00. Recipients whose country is unknown are assumed to be in
+// defaultTimezone, UTC when empty.
+func NewQuietHours(start, end, defaultTimezone string) (*QuietHours, error) {
+	// Messages may be sent from the end of the quiet hours to their start
+	allowed, err := schedule.ParseWindow(end, start, nil, defaultTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours: %w", err)
+	}
+	return &QuietHours{allowed: allowed, fallback: allowed.Location()}, nil
+}
+
+// Next returns t when a message may be sent to phoneNumber at t, otherwise
+// the end of the recipient's quiet hours
+func (q *QuietHours) Next(phoneNumber string, t time.Time) time.Time {
+	location := q.fallback
+	if _, l, ok := Country(phoneNumber); ok {
+		location = l
+	}
+	return q.allowed.In(location).NextOpen(t)
+}
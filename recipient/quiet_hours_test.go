@@ -0,0 +1,31 @@
+package recipient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHoursNext(t *testing.T) {
+	quiet, err := NewQuietHours("21:00", "09:00", "Europe/London")
+	require.NoError(t, err)
+
+	// 00:30 UTC is 03:30 in Istanbul and 20:30 the day before in New York
+	at := time.Date(2024, 5, 15, 0, 30, 0, 0, time.UTC)
+
+	istanbul, _ := time.LoadLocation("Europe/Istanbul")
+	next := quiet.Next("+905551112233", at)
+	assert.True(t, time.Date(2024, 5, 15, 9, 0, 0, 0, istanbul).Equal(next), next.String())
+
+	assert.Equal(t, at, quiet.Next("+12025550123", at), "still evening in the US")
+
+	// Unknown countries use the default timezone, 01:30 in London
+	london, _ := time.LoadLocation("Europe/London")
+	next = quiet.Next("05551112233", at)
+	assert.True(t, time.Date(2024, 5, 15, 9, 0, 0, 0, london).Equal(next), next.String())
+
+	_, err = NewQuietHours("21:00", "9am", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package recipient
+
+import (
+	"time"
+
+	"github.com/alper.meric/messaging-system/phone"
+)
+
+// timezones maps countries to the timezone their recipients are assumed to be
+// in. Countries spanning several timezones are represented by the zone most
+// of their population lives in.
+var timezones = map[string]string{
+	"AE": "Asia/Dubai",
+	"AF": "Asia/Kabul",
+	"AR": "America/Argentina/Buenos_Aires",
+	"AT": "Europe/Vienna",
+	"AU": "Australia/Sydney",
+	"AZ": "Asia/Baku",
+	"BD": "Asia/Dhaka",
+	"BE": "Europe/Brussels",
+	"BG": "Europe/Sofia",
+	"BR": "America/Sao_Paulo",
+	"CA": "America/Toronto",
+	"CH": "Europe/Zurich",
+	"CL": "America/Santiago",
+	"CN": "Asia/Shanghai",
+	"CO": "America/Bogota",
+	"CZ": "Europe/Prague",
+	"DE": "Europe/Berlin",
+	"DK": "Europe/Copenhagen",
+	"DZ": "Africa/Algiers",
+	"EG": "Africa/Cairo",
+	"ES": "Europe/Madrid",
+	"FI": "Europe/Helsinki",
+	"FR": "Europe/Paris",
+	"GB": "Europe/London",
+	"GE": "Asia/Tbilisi",
+	"GR": "Europe/Athens",
+	"HR": "Europe/Zagreb",
+	"HU": "Europe/Budapest",
+	"ID": "Asia/Jakarta",
+	"IE": "Europe/Dublin",
+	"IL": "Asia/Jerusalem",
+	"IN": "Asia/Kolkata",
+	"IQ": "Asia/Baghdad",
+	"IR": "Asia/Tehran",
+	"IT": "Europe/Rome",
+	"JO": "Asia/Amman",
+	"JP": "Asia/Tokyo",
+	"KE": "Africa/Nairobi",
+	"KR": "Asia/Seoul",
+	"KW": "Asia/Kuwait",
+	"KZ": "Asia/Almaty",
+	"LK": "Asia/Colombo",
+	"MA": "Africa/Casablanca",
+	"MX": "America/Mexico_City",
+	"MY": "Asia/Kuala_Lumpur",
+	"NG": "Africa/Lagos",
+	"NL": "Europe/Amsterdam",
+	"NO": "Europe/Oslo",
+	"NZ": "Pacific/Auckland",
+	"PE": "America/Lima",
+	"PH": "Asia/Manila",
+	"PK": "Asia/Karachi",
+	"PL": "Europe/Warsaw",
+	"PT": "Europe/Lisbon",
+	"QA": "Asia/Qatar",
+	"RO": "Europe/Bucharest",
+	"RS": "Europe/Belgrade",
+	"RU": "Europe/Moscow",
+	"SA": "Asia/Riyadh",
+	"SE": "Europe/Stockholm",
+	"SG": "Asia/Singapore",
+	"SK": "Europe/Bratislava",
+	"TH": "Asia/Bangkok",
+	"TN": "Africa/Tunis",
+	"TR": "Europe/Istanbul",
+	"UA": "Europe/Kyiv",
+	"US": "America/New_York",
+	"VN": "Asia/Ho_Chi_Minh",
+	"ZA": "Africa/Johannesburg",
+}
+
+// Country returns the ISO country code and timezone of an international
+// phone number, written with a leading + or 00. ok is false when the number
+// is not a valid international number or its country is unknown.
+func Country(phoneNumber string) (string, *time.Location, bool) {
+	number, err := phone.Parse(phoneNumber, "")
+	if err != nil {
+		return "", nil, false
+	}
+	timezone, ok := timezones[number.Region]
+	if !ok {
+		return "", nil, false
+	}
+	location, err := time.LoadLocation(timezone)
+	return number.Region, location, err == nil
+}
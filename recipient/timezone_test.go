@@ -0,0 +1,37 @@
+package recipient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountry(t *testing.T) {
+	tests := []struct {
+		phoneNumber string
+		code        string
+		timezone    string
+	}{
+		{"+905551112233", "TR", "Europe/Istanbul"},
+		{"0090 555 111 22 33", "TR", "Europe/Istanbul"},
+		{"+44 (20) 7946-0958", "GB", "Europe/London"},
+		{"+12025550123", "US", "America/New_York"},
+		{"+14165550123", "CA", "America/Toronto"},
+		{"+77011234567", "KZ", "Asia/Almaty"},
+		{"+74951234567", "RU", "Europe/Moscow"},
+		{"+971501234567", "AE", "Asia/Dubai"},
+		{"+3584012345678", "FI", "Europe/Helsinki"},
+	}
+	for _, tt := range tests {
+		code, location, ok := Country(tt.phoneNumber)
+		if assert.True(t, ok, tt.phoneNumber) {
+			assert.Equal(t, tt.code, code, tt.phoneNumber)
+			assert.Equal(t, tt.timezone, location.String(), tt.phoneNumber)
+		}
+	}
+
+	for _, phoneNumber := range []string{"05551112233", "+", "+999123", "+90abc", "", "+18765550123"} {
+		_, _, ok := Country(phoneNumber)
+		assert.False(t, ok, phoneNumber)
+	}
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/alper.meric/messaging-system/models"
+)
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a position in a sorted message listing for keyset pagination.
+// Unlike an offset it stays valid while messages are added, so paging neither
+// repeats nor skips rows.
+type Cursor struct {
+	SortBy   string     // sort field of the listing the cursor belongs to
+	SortDesc bool       // sort direction of the listing
+	Time     *time.Time // sort value, nil when sorting by ID or for an unsent message sorted by sentAt
+	ID       int        // ID of the message at the position, the tiebreaker
+	Backward bool       // list the messages before the position instead of after it
+}
+
+// cursorJSON is the encoded form of a Cursor
+type cursorJSON struct {
+	SortBy   string     `json:"s"`
+	SortDesc bool       `json:"d,omitempty"`
+	Time     *time.Time `json:"t,omitempty"`
+	ID       int        `json:"i"`
+	Backward bool       `json:"b,omitempty"`
+}
+
+// cursorAt returns a cursor positioned at message m in the listing of filter
+func (f MessageFilter) cursorAt(m models.Message, backward bool) *Cursor {
+	c := &Cursor{
+		SortBy:   f.sortField(),
+		SortDesc: f.SortDesc,
+		ID:       m.ID,
+		Backward: backward,
+	}
+
+	switch c.SortBy {
+	case SortByCreatedAt:
+		t := m.CreatedAt
+		c.Time = &t
+	case SortBySentAt:
+		if !m.SentAt.IsZero() {
+			t := m.SentAt
+			c.Time = &t
+		}
+	}
+
+	return c
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(cursorJSON(c))
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursorJSON
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := Cursor(c)
+	if err := cursor.validate(); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// validate checks that the cursor describes a position of a known sort order
+func (c Cursor) validate() error {
+	switch c.SortBy {
+	case SortByID, SortBySentAt:
+	case SortByCreatedAt:
+		if c.Time == nil {
+			return ErrInvalidCursor
+		}
+	default:
+		return ErrInvalidCursor
+	}
+
+	if c.ID < 1 {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// selectsLarger reports whether the messages the cursor selects sort above its
+// position in ascending order
+func (c Cursor) selectsLarger() bool {
+	return c.SortDesc == c.Backward
+}
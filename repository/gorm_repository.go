@@ -0,0 +1,758 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/migrations"
+	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormRepository implements the MessageRepository interface on top of GORM.
+// It holds the queries shared by the SQL backends; PostgresRepository and
+// SQLiteRepository only differ in how they connect.
+type GormRepository struct {
+	db           *gorm.DB
+	queryTimeout time.Duration
+	autoMigrate  bool
+	name         string             // prefix of span names, e.g. PostgresRepository
+	dbSystem     attribute.KeyValue // db.system attribute of spans
+}
+
+// GormOption configures optional GormRepository settings
+type GormOption func(*GormRepository)
+
+// WithQueryTimeout sets the deadline applied to every database call, 0 disables it
+func WithQueryTimeout(d time.Duration) GormOption {
+	return func(r *GormRepository) {
+		r.queryTimeout = d
+	}
+}
+
+// WithAutoMigrate sets whether pending schema migrations are applied when the
+// repository is opened, which is the default
+func WithAutoMigrate(enabled bool) GormOption {
+	return func(r *GormRepository) {
+		r.autoMigrate = enabled
+	}
+}
+
+// openGormRepository connects through dialector, lets configurePool tune the
+// connection pool and, unless disabled, applies pending migrations
+func openGormRepository(
+	dialector gorm.Dialector,
+	name string,
+	dbSystem attribute.KeyValue,
+	configurePool func(*sql.DB),
+	opts ...GormOption,
+) (*GormRepository, error) {
+	// Configure GORM logger
+	gormLogger := logger.New(
+		gormLogWriter{},
+		logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+			Colorful:                  false,
+		},
+	)
+
+	// Connect to database
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormLogger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Configure connection pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	configurePool(sqlDB)
+
+	r := &GormRepository{
+		db:          db,
+		name:        name,
+		dbSystem:    dbSystem,
+		autoMigrate: true,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.autoMigrate {
+		if err := r.Migrate(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Migrate applies pending schema migrations
+func (r *GormRepository) Migrate(ctx context.Context) error {
+	migrator, err := migrations.New(r.db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+	return nil
+}
+
+// gormLogWriter forwards GORM's slow query and error output to slog
+type gormLogWriter struct{}
+
+// Printf implements logger.Writer
+func (gormLogWriter) Printf(format string, args ...interface{}) {
+	slog.Warn(strings.TrimSpace(fmt.Sprintf(format, args...)), logging.KeyComponent, "gorm")
+}
+
+// GetDB provides access to the database object (for testing if needed)
+func (r *GormRepository) GetDB() *gorm.DB {
+	return r.db
+}
+
+// Ping checks that the database is reachable
+func (r *GormRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.GetDB().DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the database connection pool
+func (r *GormRepository) Close() error {
+	sqlDB, err := r.GetDB().DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// startSpan starts the span of a repository call, named after the backend
+func (r *GormRepository) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return startSpan(ctx, r.name+"."+method, r.dbSystem)
+}
+
+// SQL conditions of the message lifecycle, see models.Message.Status
+const (
+	// The message still is to be sent
+	condUnsent = "is_sent = FALSE AND failed_at IS NULL AND cancelled_at IS NULL"
+	// No worker holds a live claim on the message, takes the claim cutoff
+	condUnclaimed = "(claimed_at IS NULL OR claimed_at < ?)"
+	// The message's scheduled time has come, takes the current time
+	condDue = "(scheduled_at IS NULL OR scheduled_at <= ?)"
+)
+
+// ClaimUnsentMessages claims the messages that are due for sending, reserving
+// up to reserved of the batch for the oldest high-priority messages
+func (r *GormRepository) ClaimUnsentMessages(ctx context.Context, limit, reserved int) (messages []models.Message, err error) {
+	ctx, span := r.startSpan(ctx, "ClaimUnsentMessages")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	// Postgres skips rows other workers are claiming at the same moment;
+	// SQLite serialises writers, so the statement is atomic there as it is
+	lock := ""
+	if r.db.Dialector.Name() == "postgres" {
+		lock = " FOR UPDATE SKIP LOCKED"
+	}
+
+	now := time.Now()
+	claim := func(tx *gorm.DB, where, order string, limit int) ([]models.Message, error) {
+		query := `UPDATE messages SET claimed_at = ? WHERE id IN (
+			SELECT id FROM messages
+			WHERE deleted_at IS NULL AND ` + condUnsent + ` AND ` + condUnclaimed + ` AND ` + condDue + where + `
+			ORDER BY ` + order + `
+			LIMIT ?` + lock + `
+		) RETURNING *`
+
+		var claimed []models.Message
+		result := tx.Raw(query, now, now.Add(-ClaimTimeout), now, limit).Scan(&claimed)
+		return claimed, result.Error
+	}
+
+	// The reserved share goes to the oldest high-priority messages; the second
+	// statement skips them as they are claimed now, and takes further
+	// high-priority messages only when no others are waiting
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if reserved > 0 {
+			high, err := claim(tx, fmt.Sprintf(" AND priority >= %d", models.MessagePriorityHigh), "created_at, id", min(reserved, limit))
+			if err != nil {
+				return err
+			}
+			messages = append(messages, high...)
+		}
+		if len(messages) < limit {
+			order := fmt.Sprintf("CASE WHEN priority >= %d THEN 1 ELSE 0 END, priority DESC, created_at, id", models.MessagePriorityHigh)
+			rest, err := claim(tx, "", order, limit-len(messages))
+			if err != nil {
+				return err
+			}
+			messages = append(messages, rest...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim unsent messages: %w", err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sortQueue(messages)
+	return messages, nil
+}
+
+// ReleaseMessages releases the claims of messages that were not processed
+func (r *GormRepository) ReleaseMessages(ctx context.Context, ids []int) (err error) {
+	ctx, span := r.startSpan(ctx, "ReleaseMessages")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id IN ? AND is_sent = ?", ids, false).
+		Update("claimed_at", nil)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to release messages: %w", result.Error)
+	}
+	return nil
+}
+
+// CountUnsentMessages counts messages waiting to be sent
+func (r *GormRepository) CountUnsentMessages(ctx context.Context) (count int, err error) {
+	ctx, span := r.startSpan(ctx, "CountUnsentMessages")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	var total int64
+	result := r.db.WithContext(ctx).Model(&models.Message{}).Where(condUnsent).Count(&total)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to count unsent messages: %w", result.Error)
+	}
+
+	return int(total), nil
+}
+
+// GetMessage retrieves a message by its ID
+func (r *GormRepository) GetMessage(ctx context.Context, id int) (message models.Message, err error) {
+	ctx, span := r.startSpan(ctx, "GetMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Where("id = ?", id).Take(&message)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.Message{}, fmt.Errorf("%w: ID %d", ErrMessageNotFound, id)
+	}
+	if result.Error != nil {
+		return models.Message{}, fmt.Errorf("failed to retrieve message: %w", result.Error)
+	}
+
+	return message, nil
+}
+
+// GetResends retrieves the manual resends of a message, oldest first
+func (r *GormRepository) GetResends(ctx context.Context, parentID int) (messages []models.Message, err error) {
+	ctx, span := r.startSpan(ctx, "GetResends")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).Where("parent_id = ?", parentID).Order("created_at, id").Find(&messages)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to retrieve resends: %w", result.Error)
+	}
+
+	return messages, nil
+}
+
+// GetMessageByExternalID retrieves a message by the ID the external service assigned to it
+func (r *GormRepository) GetMessageByExternalID(ctx context.Context, externalID string) (message models.Message, err error) {
+	ctx, span := r.startSpan(ctx, "GetMessageByExternalID")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Where("external_msg_id = ?", externalID).
+		Order("id asc").
+		Take(&message)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.Message{}, fmt.Errorf("%w: external ID %s", ErrMessageNotFound, externalID)
+	}
+	if result.Error != nil {
+		return models.Message{}, fmt.Errorf("failed to retrieve message: %w", result.Error)
+	}
+
+	return message, nil
+}
+
+// MarkMessageAsSent marks a message as sent
+func (r *GormRepository) MarkMessageAsSent(ctx context.Context, id int, externalMsgID string) (err error) {
+	ctx, span := r.startSpan(ctx, "MarkMessageAsSent")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_sent":         true,
+			"sent_at":         time.Now(),
+			"external_msg_id": externalMsgID,
+			"attempts":        gorm.Expr("attempts + 1"),
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark message as sent: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("message with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// DeferMessage postpones a claimed message until the given time and releases the claim
+func (r *GormRepository) DeferMessage(ctx context.Context, id int, until time.Time) (err error) {
+	ctx, span := r.startSpan(ctx, "DeferMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id = ? AND is_sent = ?", id, false).
+		Updates(map[string]interface{}{
+			"scheduled_at": until,
+			"claimed_at":   nil,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to defer message: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("unsent message with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// RecordSendFailure records a failed delivery attempt. Permanently failed
+// messages are no longer claimed by ClaimUnsentMessages.
+func (r *GormRepository) RecordSendFailure(ctx context.Context, id int, reason string, permanent bool) (err error) {
+	ctx, span := r.startSpan(ctx, "RecordSendFailure")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	updates := map[string]interface{}{
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": reason,
+		"claimed_at": nil,
+	}
+	if permanent {
+		updates["failed_at"] = time.Now()
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id = ? AND is_sent = ?", id, false).
+		Updates(updates)
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to record send failure: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("unsent message with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// CancelMessage cancels a message that has not been claimed for sending yet
+func (r *GormRepository) CancelMessage(ctx context.Context, id int) (err error) {
+	ctx, span := r.startSpan(ctx, "CancelMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	now := time.Now()
+	result := r.editable(ctx, id, now).Update("cancelled_at", now)
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel message: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return r.notEditable(ctx, id)
+	}
+	return nil
+}
+
+// UpdateMessage changes a message that has not been claimed for sending yet
+func (r *GormRepository) UpdateMessage(ctx context.Context, id int, update MessageUpdate) (err error) {
+	ctx, span := r.startSpan(ctx, "UpdateMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	updates := map[string]interface{}{}
+	if update.Content != nil {
+		updates["content"] = *update.Content
+	}
+	if update.PhoneNumber != nil {
+		updates["phone_number"] = *update.PhoneNumber
+	}
+	if update.Category != nil {
+		updates["category"] = *update.Category
+	}
+	if update.Priority != nil {
+		updates["priority"] = *update.Priority
+	}
+	if update.CountryCode != nil {
+		updates["country_code"] = *update.CountryCode
+	}
+	if update.Encoding != nil {
+		updates["encoding"] = *update.Encoding
+	}
+	if update.Segments != nil {
+		updates["segments"] = *update.Segments
+	}
+	if update.Transliteration != nil {
+		updates["transliteration"] = *update.Transliteration
+	}
+	if update.ScheduledAt != nil {
+		updates["scheduled_at"] = *update.ScheduledAt
+	}
+	if update.ClearSchedule {
+		updates["scheduled_at"] = nil
+	}
+	if len(updates) == 0 {
+		return errors.New("no changes to update")
+	}
+
+	query := r.editable(ctx, id, time.Now())
+	if update.ExpectedContent != nil {
+		query = query.Where("content = ?", *update.ExpectedContent)
+	}
+	if update.ExpectedTransliteration != nil {
+		query = query.Where("COALESCE(transliteration, '') = ?", *update.ExpectedTransliteration)
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update message: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return r.notEditable(ctx, id)
+	}
+	return nil
+}
+
+// DeleteMessage soft deletes a message that has not been sent or claimed for sending
+func (r *GormRepository) DeleteMessage(ctx context.Context, id int) (err error) {
+	ctx, span := r.startSpan(ctx, "DeleteMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	// Cancelled and permanently failed messages are no longer claimed and may go as well
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND is_sent = ?", id, false).
+		Where(condUnclaimed, time.Now().Add(-ClaimTimeout)).
+		Delete(&models.Message{})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete message: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return r.notEditable(ctx, id)
+	}
+	return nil
+}
+
+// editable scopes an update to message id while it is unsent and unclaimed.
+// The conditions are part of the UPDATE itself, so a worker claiming the
+// message at the same moment either comes first and the update matches no
+// row, or comes second and finds the change already made.
+func (r *GormRepository) editable(ctx context.Context, id int, now time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&models.Message{}).
+		Where("id = ?", id).
+		Where(condUnsent).
+		Where(condUnclaimed, now.Add(-ClaimTimeout))
+}
+
+// notEditable explains why a conditional update of message id matched no row
+func (r *GormRepository) notEditable(ctx context.Context, id int) error {
+	message, err := r.GetMessage(ctx, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if queued(message) && !claimed(message, now) {
+		// Only the expected content can have kept the row from matching
+		return fmt.Errorf("%w: message %d", ErrMessageChanged, id)
+	}
+	return fmt.Errorf("%w: message %d is %s", ErrMessageNotEditable, id, editState(message, now))
+}
+
+// GetSentMessages retrieves sent messages with pagination
+func (r *GormRepository) GetSentMessages(ctx context.Context, page, limit int) ([]models.Message, int, error) {
+	result, err := r.ListMessages(ctx, MessageFilter{
+		Status:   models.MessageStatusSent,
+		SortBy:   SortBySentAt,
+		SortDesc: true,
+		Page:     page,
+		Limit:    limit,
+		Count:    CountExact,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Messages, *result.Total, nil
+}
+
+// ListMessages retrieves a page of the messages matching filter
+func (r *GormRepository) ListMessages(ctx context.Context, filter MessageFilter) (page MessagePage, err error) {
+	ctx, span := r.startSpan(ctx, "ListMessages")
+	defer func() { tracing.End(span, err) }()
+
+	if err := filter.Validate(); err != nil {
+		return MessagePage{}, err
+	}
+
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	// A new session lets the filtered query be reused for fetching and counting
+	db := r.db.WithContext(ctx).Model(&models.Message{}).Scopes(r.filterScope(filter)).Session(&gorm.Session{})
+
+	var messages []models.Message
+	result := db.Scopes(r.cursorScope(filter.Cursor)).
+		Order(r.orderClause(filter.fetchOrder())).
+		Offset(filter.offset()).
+		Limit(filter.Limit + 1).
+		Find(&messages)
+
+	if result.Error != nil {
+		return MessagePage{}, fmt.Errorf("failed to retrieve messages: %w", result.Error)
+	}
+
+	page = filter.newPage(messages)
+
+	switch filter.countMode() {
+	case CountExact:
+		page.Total, err = r.count(db)
+	case CountEstimated:
+		if r.db.Dialector.Name() == "postgres" {
+			page.Total, err = r.estimateCount(ctx, db)
+			page.TotalEstimated = true
+		} else {
+			// Without planner statistics to rely on, SQLite counts exactly
+			page.Total, err = r.count(db)
+		}
+	}
+	if err != nil {
+		return MessagePage{}, err
+	}
+
+	return page, nil
+}
+
+// count returns the exact number of messages matched by db
+func (r *GormRepository) count(db *gorm.DB) (*int, error) {
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count messages: %w", err)
+	}
+	count := int(total)
+	return &count, nil
+}
+
+// estimateCount returns the planner's row estimate for the messages matched by
+// db, which avoids scanning them all like COUNT(*) does
+func (r *GormRepository) estimateCount(ctx context.Context, db *gorm.DB) (*int, error) {
+	stmt := db.Session(&gorm.Session{DryRun: true}).Find(&[]models.Message{}).Statement
+
+	var plan string
+	err := r.db.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Row().Scan(&plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate message count: %w", err)
+	}
+
+	var explained []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explained); err != nil || len(explained) == 0 {
+		return nil, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+
+	count := int(explained[0].Plan.Rows)
+	return &count, nil
+}
+
+// cursorScope restricts the query to the messages on the requested side of
+// cursor, comparing (sort value, id) so it can be served by the sort indexes
+func (r *GormRepository) cursorScope(cursor *Cursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if cursor == nil {
+			return db
+		}
+
+		larger := cursor.selectsLarger()
+		op := "<"
+		if larger {
+			op = ">"
+		}
+
+		switch cursor.SortBy {
+		case SortByID:
+			return db.Where("id "+op+" ?", cursor.ID)
+		case SortByCreatedAt:
+			return db.Where("(created_at, id) "+op+" (?, ?)", *cursor.Time, cursor.ID)
+		}
+
+		// A missing sent_at sorts above every send time, see orderClause
+		switch {
+		case cursor.Time == nil && larger:
+			return db.Where("sent_at IS NULL AND id > ?", cursor.ID)
+		case cursor.Time == nil:
+			return db.Where("(sent_at IS NOT NULL OR id < ?)", cursor.ID)
+		case larger:
+			return db.Where("((sent_at, id) > (?, ?) OR sent_at IS NULL)", *cursor.Time, cursor.ID)
+		default:
+			return db.Where("(sent_at, id) < (?, ?)", *cursor.Time, cursor.ID)
+		}
+	}
+}
+
+// filterScope turns the conditions of filter into WHERE clauses
+func (r *GormRepository) filterScope(filter MessageFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch filter.Status {
+		case models.MessageStatusSent:
+			db = db.Where("is_sent = ?", true)
+		case models.MessageStatusUnsent:
+			db = db.Where(condUnsent)
+		case models.MessageStatusFailed:
+			db = db.Where("is_sent = ? AND failed_at IS NOT NULL", false)
+		case models.MessageStatusCancelled:
+			db = db.Where("is_sent = ? AND failed_at IS NULL AND cancelled_at IS NOT NULL", false)
+		}
+
+		if filter.PhoneNumber != "" {
+			db = db.Where("phone_number = ?", filter.PhoneNumber)
+		}
+		if filter.PhonePrefix != "" {
+			db = db.Where(`phone_number LIKE ? ESCAPE '\'`, escapeLike(filter.PhonePrefix)+"%")
+		}
+		if filter.Content != "" {
+			// SQLite's LIKE is already case-insensitive, Postgres needs ILIKE
+			operator := "LIKE"
+			if r.db.Dialector.Name() == "postgres" {
+				operator = "ILIKE"
+			}
+			db = db.Where("content "+operator+` ? ESCAPE '\'`, "%"+escapeLike(filter.Content)+"%")
+		}
+		if filter.ExternalMsgID != "" {
+			db = db.Where("external_msg_id = ?", filter.ExternalMsgID)
+		}
+
+		if filter.CreatedFrom != nil {
+			db = db.Where("created_at >= ?", *filter.CreatedFrom)
+		}
+		if filter.CreatedTo != nil {
+			db = db.Where("created_at < ?", *filter.CreatedTo)
+		}
+		if filter.SentFrom != nil {
+			db = db.Where("sent_at >= ?", *filter.SentFrom)
+		}
+		if filter.SentTo != nil {
+			db = db.Where("sent_at < ?", *filter.SentTo)
+		}
+
+		return db
+	}
+}
+
+// orderClause returns the ORDER BY clause of filter, with the ID as tiebreaker.
+// Unsent messages have no sent_at; like Postgres does by default they sort as
+// if it was later than any other, which SQLite has to be told explicitly.
+func (r *GormRepository) orderClause(filter MessageFilter) string {
+	direction := "ASC"
+	if filter.SortDesc {
+		direction = "DESC"
+	}
+
+	switch filter.sortField() {
+	case SortBySentAt:
+		nulls := ""
+		if r.db.Dialector.Name() != "postgres" {
+			nulls = " NULLS LAST"
+			if filter.SortDesc {
+				nulls = " NULLS FIRST"
+			}
+		}
+		return "sent_at " + direction + nulls + ", id " + direction
+	case SortByID:
+		return "id " + direction
+	default:
+		return "created_at " + direction + ", id " + direction
+	}
+}
+
+// AddMessage adds a new message
+func (r *GormRepository) AddMessage(ctx context.Context, message models.Message) (id int, err error) {
+	ctx, span := r.startSpan(ctx, "AddMessage")
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	// Set defaults
+	message.IsSent = false
+	message.CreatedAt = time.Now()
+
+	// Add message to database
+	result := r.db.WithContext(ctx).Create(&message)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to add message: %w", result.Error)
+	}
+
+	return message.ID, nil
+}
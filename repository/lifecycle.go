@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/alper.meric/messaging-system/models"
+)
+
+// The message lifecycle checks in Go, matching the SQL conditions of GormRepository
+
+// queued reports whether message m still is to be sent, like condUnsent
+func queued(m models.Message) bool {
+	return m.Status() == models.MessageStatusUnsent
+}
+
+// claimed reports whether a worker holds a live claim on message m, the opposite of condUnclaimed
+func claimed(m models.Message, now time.Time) bool {
+	return m.ClaimedAt != nil && !m.ClaimedAt.Before(now.Add(-ClaimTimeout))
+}
+
+// due reports whether the scheduled time of message m has come, like condDue
+func due(m models.Message, now time.Time) bool {
+	return m.ScheduledAt == nil || !m.ScheduledAt.After(now)
+}
+
+// editState describes the state that keeps message m from being changed
+func editState(m models.Message, now time.Time) string {
+	if queued(m) && claimed(m, now) {
+		return "claimed for sending"
+	}
+	return m.Status()
+}
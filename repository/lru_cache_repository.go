@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alper.meric/messaging-system/metrics"
+)
+
+// LRUCacheRepository implements the CacheRepository interface with a bounded
+// in-process cache that evicts the least recently used entry when full
+type LRUCacheRepository struct {
+	mutex   sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently used entry
+	metrics metrics.Metrics
+	now     func() time.Time
+}
+
+// defaultLRUCacheSize is used when no positive size is configured
+const defaultLRUCacheSize = 10000
+
+// lruEntry is a single cached message
+type lruEntry struct {
+	externalID string
+	message    CachedMessage
+	expiresAt  time.Time
+}
+
+// LRUOption configures optional LRUCacheRepository dependencies
+type LRUOption func(*LRUCacheRepository)
+
+// WithLRUMetrics sets the metrics recorder used for cache hit/miss counters
+func WithLRUMetrics(m metrics.Metrics) LRUOption {
+	return func(r *LRUCacheRepository) {
+		r.metrics = m
+	}
+}
+
+// NewLRUCacheRepository creates a new LRUCacheRepository holding at most size entries
+func NewLRUCacheRepository(size int, opts ...LRUOption) *LRUCacheRepository {
+	if size <= 0 {
+		size = defaultLRUCacheSize
+	}
+
+	r := &LRUCacheRepository{
+		size:    size,
+		ttl:     cacheTTL,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		metrics: metrics.NewNoop(),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// CacheMessageID saves a message's ID and send time, evicting the oldest entry if the cache is full
+func (r *LRUCacheRepository) CacheMessageID(ctx context.Context, externalID string, messageID int, sentAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry := &lruEntry{
+		externalID: externalID,
+		message:    CachedMessage{ID: messageID, SentAt: sentAt},
+		expiresAt:  r.now().Add(r.ttl),
+	}
+
+	if element, ok := r.entries[externalID]; ok {
+		element.Value = entry
+		r.order.MoveToFront(element)
+		return nil
+	}
+
+	r.entries[externalID] = r.order.PushFront(entry)
+	if r.order.Len() > r.size {
+		r.remove(r.order.Back())
+	}
+	return nil
+}
+
+// GetCachedMessage retrieves a cached message by external ID
+func (r *LRUCacheRepository) GetCachedMessage(ctx context.Context, externalID string) (CachedMessage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	element, ok := r.entries[externalID]
+	if ok && r.now().After(element.Value.(*lruEntry).expiresAt) {
+		r.remove(element)
+		ok = false
+	}
+	if !ok {
+		r.metrics.CacheMiss()
+		return CachedMessage{}, fmt.Errorf("%w: %s", ErrCacheMiss, externalID)
+	}
+
+	r.metrics.CacheHit()
+	r.order.MoveToFront(element)
+	return element.Value.(*lruEntry).message, nil
+}
+
+// Len returns the number of cached entries
+func (r *LRUCacheRepository) Len() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.order.Len()
+}
+
+// remove drops an entry from both the list and the index
+func (r *LRUCacheRepository) remove(element *list.Element) {
+	r.order.Remove(element)
+	delete(r.entries, element.Value.(*lruEntry).externalID)
+}
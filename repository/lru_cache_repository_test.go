@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCacheRepository(t *testing.T) {
+	ctx := context.Background()
+	sentAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("stores and retrieves entries", func(t *testing.T) {
+		recorder := metrics.NewMemoryMetrics()
+		cache := NewLRUCacheRepository(10, WithLRUMetrics(recorder))
+
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-1", 1, sentAt))
+
+		got, err := cache.GetCachedMessage(ctx, "ext-1")
+		assert.NoError(t, err)
+		assert.Equal(t, CachedMessage{ID: 1, SentAt: sentAt}, got)
+
+		_, err = cache.GetCachedMessage(ctx, "ext-2")
+		assert.ErrorIs(t, err, ErrCacheMiss)
+
+		assert.Equal(t, 1, recorder.Count(metrics.CacheHits))
+		assert.Equal(t, 1, recorder.Count(metrics.CacheMisses))
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		cache := NewLRUCacheRepository(2)
+
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-1", 1, sentAt))
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-2", 2, sentAt))
+
+		// Reading ext-1 makes ext-2 the least recently used entry
+		_, err := cache.GetCachedMessage(ctx, "ext-1")
+		assert.NoError(t, err)
+
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-3", 3, sentAt))
+		assert.Equal(t, 2, cache.Len())
+
+		_, err = cache.GetCachedMessage(ctx, "ext-2")
+		assert.ErrorIs(t, err, ErrCacheMiss)
+		_, err = cache.GetCachedMessage(ctx, "ext-1")
+		assert.NoError(t, err)
+		_, err = cache.GetCachedMessage(ctx, "ext-3")
+		assert.NoError(t, err)
+	})
+
+	t.Run("updates existing entries", func(t *testing.T) {
+		cache := NewLRUCacheRepository(2)
+
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-1", 1, sentAt))
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-1", 1, sentAt.Add(time.Minute)))
+		assert.Equal(t, 1, cache.Len())
+
+		got, err := cache.GetCachedMessage(ctx, "ext-1")
+		assert.NoError(t, err)
+		assert.Equal(t, sentAt.Add(time.Minute), got.SentAt)
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		now := time.Now()
+		cache := NewLRUCacheRepository(10)
+		cache.now = func() time.Time { return now }
+
+		assert.NoError(t, cache.CacheMessageID(ctx, "ext-1", 1, sentAt))
+
+		now = now.Add(cacheTTL + time.Second)
+		_, err := cache.GetCachedMessage(ctx, "ext-1")
+		assert.ErrorIs(t, err, ErrCacheMiss)
+		assert.Equal(t, 0, cache.Len())
+	})
+}
+
+func TestNoopCacheRepository(t *testing.T) {
+	cache := NewNoopCacheRepository()
+
+	assert.NoError(t, cache.CacheMessageID(context.Background(), "ext-1", 1, time.Now()))
+
+	_, err := cache.GetCachedMessage(context.Background(), "ext-1")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
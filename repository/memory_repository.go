@@ -0,0 +1,503 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alper.meric/messaging-system/models"
+	"gorm.io/gorm"
+)
+
+// MemoryRepository implements the MessageRepository interface in memory.
+// It follows the ordering and pagination of PostgresRepository and is meant
+// for local development and tests; nothing survives a restart.
+type MemoryRepository struct {
+	mutex    sync.RWMutex
+	messages map[int]models.Message
+	nextID   int
+}
+
+// NewMemoryRepository creates a new, empty MemoryRepository instance
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		messages: make(map[int]models.Message),
+		nextID:   1,
+	}
+}
+
+// ClaimUnsentMessages claims the messages that are due for sending, reserving
+// up to reserved of the batch for the oldest high-priority messages
+func (r *MemoryRepository) ClaimUnsentMessages(ctx context.Context, limit, reserved int) ([]models.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim unsent messages: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	var high, rest []models.Message
+	for _, m := range r.claimable(now) {
+		if m.Priority >= models.MessagePriorityHigh {
+			high = append(high, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+	reserved = max(0, min(reserved, limit, len(high)))
+	messages := append(high[:reserved:reserved], paginate(append(rest, high[reserved:]...), 0, limit-reserved)...)
+	sortQueue(messages)
+
+	for i := range messages {
+		claimedAt := now
+		messages[i].ClaimedAt = &claimedAt
+		r.messages[messages[i].ID] = messages[i]
+	}
+	return messages, nil
+}
+
+// claimable returns the messages that are due and not claimed in queue order
+func (r *MemoryRepository) claimable(now time.Time) []models.Message {
+	messages := r.filter(func(m models.Message) bool {
+		return queued(m) && !claimed(m, now) && due(m, now)
+	})
+	sortQueue(messages)
+
+	return messages
+}
+
+// ReleaseMessages releases the claims of messages that were not processed
+func (r *MemoryRepository) ReleaseMessages(ctx context.Context, ids []int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to release messages: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, id := range ids {
+		if message, ok := r.messages[id]; ok && !message.IsSent {
+			message.ClaimedAt = nil
+			r.messages[id] = message
+		}
+	}
+	return nil
+}
+
+// CountUnsentMessages counts messages waiting to be sent
+func (r *MemoryRepository) CountUnsentMessages(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("failed to count unsent messages: %w", err)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.filter(queued)), nil
+}
+
+// GetMessage retrieves a message by its ID
+func (r *MemoryRepository) GetMessage(ctx context.Context, id int) (models.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Message{}, fmt.Errorf("failed to retrieve message: %w", err)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	message, ok := r.messages[id]
+	if !ok || message.DeletedAt.Valid {
+		return models.Message{}, fmt.Errorf("%w: ID %d", ErrMessageNotFound, id)
+	}
+	return message, nil
+}
+
+// GetResends retrieves the manual resends of a message, oldest first
+func (r *MemoryRepository) GetResends(ctx context.Context, parentID int) ([]models.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve resends: %w", err)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var resends []models.Message
+	for _, message := range r.messages {
+		if message.ParentID != nil && *message.ParentID == parentID && !message.DeletedAt.Valid {
+			resends = append(resends, message)
+		}
+	}
+	sortMessages(resends, SortByCreatedAt, false)
+	return resends, nil
+}
+
+// GetMessageByExternalID retrieves a message by the ID the external service assigned to it
+func (r *MemoryRepository) GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Message{}, fmt.Errorf("failed to retrieve message: %w", err)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	messages := r.filter(func(m models.Message) bool { return externalID != "" && m.ExternalMsgID == externalID })
+	if len(messages) == 0 {
+		return models.Message{}, fmt.Errorf("%w: external ID %s", ErrMessageNotFound, externalID)
+	}
+	sortMessages(messages, SortByID, false)
+	return messages[0], nil
+}
+
+// MarkMessageAsSent marks a message as sent
+func (r *MemoryRepository) MarkMessageAsSent(ctx context.Context, id int, externalMsgID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to mark message as sent: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return fmt.Errorf("message with ID %d not found", id)
+	}
+
+	now := time.Now()
+	message.IsSent = true
+	message.SentAt = now
+	message.ExternalMsgID = externalMsgID
+	message.Attempts++
+	message.UpdatedAt = now
+	r.messages[id] = message
+
+	return nil
+}
+
+// DeferMessage postpones a claimed message until the given time and releases the claim
+func (r *MemoryRepository) DeferMessage(ctx context.Context, id int, until time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to defer message: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok || message.IsSent {
+		return fmt.Errorf("unsent message with ID %d not found", id)
+	}
+
+	message.ScheduledAt = &until
+	message.ClaimedAt = nil
+	message.UpdatedAt = time.Now()
+	r.messages[id] = message
+
+	return nil
+}
+
+// RecordSendFailure records a failed delivery attempt. Permanently failed
+// messages are no longer claimed by ClaimUnsentMessages.
+func (r *MemoryRepository) RecordSendFailure(ctx context.Context, id int, reason string, permanent bool) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to record send failure: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok || message.IsSent {
+		return fmt.Errorf("unsent message with ID %d not found", id)
+	}
+
+	now := time.Now()
+	message.Attempts++
+	message.LastError = reason
+	message.ClaimedAt = nil
+	if permanent {
+		message.FailedAt = &now
+	}
+	message.UpdatedAt = now
+	r.messages[id] = message
+
+	return nil
+}
+
+// CancelMessage cancels a message that has not been claimed for sending yet
+func (r *MemoryRepository) CancelMessage(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to cancel message: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	message, err := r.editable(id, now)
+	if err != nil {
+		return err
+	}
+
+	message.CancelledAt = &now
+	message.UpdatedAt = now
+	r.messages[id] = message
+	return nil
+}
+
+// UpdateMessage changes a message that has not been claimed for sending yet
+func (r *MemoryRepository) UpdateMessage(ctx context.Context, id int, update MessageUpdate) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+	if update.Content == nil && update.PhoneNumber == nil && update.Category == nil && update.Priority == nil && update.Transliteration == nil && update.ScheduledAt == nil && !update.ClearSchedule {
+		return errors.New("no changes to update")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	message, err := r.editable(id, now)
+	if err != nil {
+		return err
+	}
+	if (update.ExpectedContent != nil && *update.ExpectedContent != message.Content) ||
+		(update.ExpectedTransliteration != nil && *update.ExpectedTransliteration != message.Transliteration) {
+		return fmt.Errorf("%w: message %d", ErrMessageChanged, id)
+	}
+
+	if update.Content != nil {
+		message.Content = *update.Content
+	}
+	if update.PhoneNumber != nil {
+		message.PhoneNumber = *update.PhoneNumber
+	}
+	if update.Category != nil {
+		message.Category = *update.Category
+	}
+	if update.Priority != nil {
+		message.Priority = *update.Priority
+	}
+	if update.CountryCode != nil {
+		message.CountryCode = *update.CountryCode
+	}
+	if update.Encoding != nil {
+		message.Encoding = *update.Encoding
+	}
+	if update.Segments != nil {
+		message.Segments = *update.Segments
+	}
+	if update.Transliteration != nil {
+		message.Transliteration = *update.Transliteration
+	}
+	if update.ScheduledAt != nil {
+		scheduledAt := *update.ScheduledAt
+		message.ScheduledAt = &scheduledAt
+	}
+	if update.ClearSchedule {
+		message.ScheduledAt = nil
+	}
+	message.UpdatedAt = now
+	r.messages[id] = message
+	return nil
+}
+
+// DeleteMessage soft deletes a message that has not been sent or claimed for sending
+func (r *MemoryRepository) DeleteMessage(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	message, ok := r.messages[id]
+	if !ok || message.DeletedAt.Valid {
+		return fmt.Errorf("%w: ID %d", ErrMessageNotFound, id)
+	}
+	// Cancelled and permanently failed messages are no longer claimed and may go as well
+	if message.IsSent || claimed(message, now) {
+		return fmt.Errorf("%w: message %d is %s", ErrMessageNotEditable, id, editState(message, now))
+	}
+
+	message.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	r.messages[id] = message
+	return nil
+}
+
+// editable returns message id if it is unsent and unclaimed
+func (r *MemoryRepository) editable(id int, now time.Time) (models.Message, error) {
+	message, ok := r.messages[id]
+	if !ok || message.DeletedAt.Valid {
+		return models.Message{}, fmt.Errorf("%w: ID %d", ErrMessageNotFound, id)
+	}
+	if !queued(message) || claimed(message, now) {
+		return models.Message{}, fmt.Errorf("%w: message %d is %s", ErrMessageNotEditable, id, editState(message, now))
+	}
+	return message, nil
+}
+
+// GetSentMessages retrieves sent messages with pagination, most recently sent first
+func (r *MemoryRepository) GetSentMessages(ctx context.Context, page, limit int) ([]models.Message, int, error) {
+	result, err := r.ListMessages(ctx, MessageFilter{
+		Status:   models.MessageStatusSent,
+		SortBy:   SortBySentAt,
+		SortDesc: true,
+		Page:     page,
+		Limit:    limit,
+		Count:    CountExact,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Messages, *result.Total, nil
+}
+
+// ListMessages retrieves a page of the messages matching filter. Counts are always exact.
+func (r *MemoryRepository) ListMessages(ctx context.Context, filter MessageFilter) (MessagePage, error) {
+	if err := filter.Validate(); err != nil {
+		return MessagePage{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return MessagePage{}, fmt.Errorf("failed to retrieve messages: %w", err)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	matching := r.filter(filter.Matches)
+
+	order := filter.fetchOrder()
+	less := messageLess(order.sortField(), order.SortDesc)
+
+	messages := matching
+	if cursor := filter.Cursor; cursor != nil {
+		position := models.Message{ID: cursor.ID}
+		if cursor.Time != nil {
+			position.CreatedAt = *cursor.Time
+			position.SentAt = *cursor.Time
+		}
+		messages = make([]models.Message, 0, len(matching))
+		for _, m := range matching {
+			if less(position, m) {
+				messages = append(messages, m)
+			}
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return less(messages[i], messages[j]) })
+
+	page := filter.newPage(paginate(messages, filter.offset(), filter.Limit+1))
+	if filter.countMode() != CountNone {
+		total := len(matching)
+		page.Total = &total
+	}
+	return page, nil
+}
+
+// AddMessage adds a new message
+func (r *MemoryRepository) AddMessage(ctx context.Context, message models.Message) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("failed to add message: %w", err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Set defaults
+	now := time.Now()
+	message.IsSent = false
+	if message.Category == "" {
+		message.Category = models.MessageCategoryTransactional
+	}
+	message.CreatedAt = now
+	message.UpdatedAt = now
+
+	if message.ID == 0 {
+		message.ID = r.nextID
+	} else if _, exists := r.messages[message.ID]; exists {
+		return 0, fmt.Errorf("failed to add message: message with ID %d already exists", message.ID)
+	}
+	if message.ID >= r.nextID {
+		r.nextID = message.ID + 1
+	}
+
+	r.messages[message.ID] = message
+	return message.ID, nil
+}
+
+// filter returns copies of the stored messages matching keep. Soft-deleted
+// messages are skipped like GORM does.
+func (r *MemoryRepository) filter(keep func(models.Message) bool) []models.Message {
+	messages := make([]models.Message, 0, len(r.messages))
+	for _, m := range r.messages {
+		if m.DeletedAt.Valid || !keep(m) {
+			continue
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// sortMessages orders messages by field, see messageLess
+func sortMessages(messages []models.Message, field string, desc bool) {
+	less := messageLess(field, desc)
+	sort.Slice(messages, func(i, j int) bool { return less(messages[i], messages[j]) })
+}
+
+// sortQueue sorts messages in the order they are sent, by priority and age
+func sortQueue(messages []models.Message) {
+	less := messageLess(SortByCreatedAt, false)
+	sort.Slice(messages, func(i, j int) bool {
+		if messages[i].Priority != messages[j].Priority {
+			return messages[i].Priority > messages[j].Priority
+		}
+		return less(messages[i], messages[j])
+	})
+}
+
+// messageLess returns the ordering of messages by field with the ID as
+// tiebreaker. A message that has not been sent sorts after all sent ones by
+// sent time, as NULL does in Postgres.
+func messageLess(field string, desc bool) func(a, b models.Message) bool {
+	return func(a, b models.Message) bool {
+		if desc {
+			a, b = b, a
+		}
+
+		switch field {
+		case SortBySentAt:
+			if a.SentAt.IsZero() != b.SentAt.IsZero() {
+				return b.SentAt.IsZero()
+			}
+			if !a.SentAt.Equal(b.SentAt) {
+				return a.SentAt.Before(b.SentAt)
+			}
+		case SortByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.ID < b.ID
+	}
+}
+
+// paginate returns at most limit messages starting at offset
+func paginate(messages []models.Message, offset, limit int) []models.Message {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(messages) {
+		return []models.Message{}
+	}
+	messages = messages[offset:]
+	if limit >= 0 && limit < len(messages) {
+		messages = messages[:limit]
+	}
+	return messages
+}
@@ -0,0 +1,33 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.MessageRepository {
+		return repository.NewMemoryRepository()
+	})
+}
+
+func TestMemoryRepositoryReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+
+	id, err := repo.AddMessage(ctx, models.Message{PhoneNumber: "+905551112233", Content: "hello"})
+	assert.NoError(t, err)
+
+	messages, err := repo.ClaimUnsentMessages(ctx, 10, 0)
+	assert.NoError(t, err)
+	messages[0].Content = "changed"
+
+	message, err := repo.GetMessage(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", message.Content, "callers must not be able to modify stored messages")
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alper.meric/messaging-system/models"
+)
+
+// Sort fields accepted by MessageFilter.SortBy
+const (
+	SortByCreatedAt = "createdAt"
+	SortBySentAt    = "sentAt"
+	SortByID        = "id"
+)
+
+// Count modes accepted by MessageFilter.Count
+const (
+	CountExact     = "exact"     // COUNT(*) of all matching messages
+	CountEstimated = "estimated" // planner estimate where the database offers one, exact otherwise
+	CountNone      = "none"      // no total at all
+)
+
+// MessageFilter selects, orders and paginates messages for ListMessages.
+// Zero values do not restrict the result.
+type MessageFilter struct {
+	Status        string     // models.MessageStatus* value, empty for any status
+	PhoneNumber   string     // exact match
+	PhonePrefix   string     // phone numbers starting with this prefix
+	Content       string     // case-insensitive substring of the content
+	ExternalMsgID string     // exact match
+	CreatedFrom   *time.Time // created at or after
+	CreatedTo     *time.Time // created before
+	SentFrom      *time.Time // sent at or after
+	SentTo        *time.Time // sent before
+
+	SortBy   string // SortBy* value, SortByCreatedAt when empty
+	SortDesc bool
+
+	Page   int     // 1-based, ignored when Cursor is set
+	Cursor *Cursor // keyset pagination: continue from this position instead of using Page
+	Limit  int
+
+	Count string // Count* value, CountExact when empty
+}
+
+// MessagePage is one page of a message listing
+type MessagePage struct {
+	Messages []models.Message
+
+	Total          *int // nil when not counted
+	TotalEstimated bool
+
+	Next *Cursor // nil on the last page
+	Prev *Cursor // nil on the first page
+}
+
+// Validate checks that the filter only uses known values
+func (f MessageFilter) Validate() error {
+	switch f.Status {
+	case "", models.MessageStatusSent, models.MessageStatusUnsent, models.MessageStatusFailed, models.MessageStatusCancelled:
+	default:
+		return fmt.Errorf("invalid status %q, use sent, unsent, failed or cancelled", f.Status)
+	}
+
+	switch f.SortBy {
+	case "", SortByCreatedAt, SortBySentAt, SortByID:
+	default:
+		return fmt.Errorf("invalid sort field %q, use createdAt, sentAt or id", f.SortBy)
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return errors.New("createdFrom must be before createdTo")
+	}
+	if f.SentFrom != nil && f.SentTo != nil && !f.SentFrom.Before(*f.SentTo) {
+		return errors.New("sentFrom must be before sentTo")
+	}
+
+	if (f.Cursor == nil && f.Page < 1) || f.Limit < 1 {
+		return errors.New("page and limit must be positive")
+	}
+
+	if f.Cursor != nil {
+		if err := f.Cursor.validate(); err != nil {
+			return err
+		}
+		if f.Cursor.SortBy != f.sortField() || f.Cursor.SortDesc != f.SortDesc {
+			return errors.New("cursor belongs to a listing with a different sort order")
+		}
+	}
+
+	switch f.Count {
+	case "", CountExact, CountEstimated, CountNone:
+	default:
+		return fmt.Errorf("invalid count %q, use exact, estimated or none", f.Count)
+	}
+
+	return nil
+}
+
+// Matches reports whether a message satisfies the filter conditions,
+// ignoring sorting and pagination
+func (f MessageFilter) Matches(m models.Message) bool {
+	switch {
+	case f.Status != "" && m.Status() != f.Status:
+		return false
+	case f.PhoneNumber != "" && m.PhoneNumber != f.PhoneNumber:
+		return false
+	case f.PhonePrefix != "" && !strings.HasPrefix(m.PhoneNumber, f.PhonePrefix):
+		return false
+	case f.Content != "" && !strings.Contains(strings.ToLower(m.Content), strings.ToLower(f.Content)):
+		return false
+	case f.ExternalMsgID != "" && m.ExternalMsgID != f.ExternalMsgID:
+		return false
+	case f.CreatedFrom != nil && m.CreatedAt.Before(*f.CreatedFrom):
+		return false
+	case f.CreatedTo != nil && !m.CreatedAt.Before(*f.CreatedTo):
+		return false
+	case (f.SentFrom != nil || f.SentTo != nil) && m.SentAt.IsZero():
+		return false
+	case f.SentFrom != nil && m.SentAt.Before(*f.SentFrom):
+		return false
+	case f.SentTo != nil && !m.SentAt.Before(*f.SentTo):
+		return false
+	}
+	return true
+}
+
+// sortField returns the sort field, defaulting to the creation time
+func (f MessageFilter) sortField() string {
+	if f.SortBy == "" {
+		return SortByCreatedAt
+	}
+	return f.SortBy
+}
+
+// offset returns the number of messages skipped before the requested page
+func (f MessageFilter) offset() int {
+	if f.Cursor != nil {
+		return 0
+	}
+	return (f.Page - 1) * f.Limit
+}
+
+// countMode returns the count mode, defaulting to an exact count
+func (f MessageFilter) countMode() string {
+	if f.Count == "" {
+		return CountExact
+	}
+	return f.Count
+}
+
+// fetchOrder returns the filter whose sort order the messages are fetched in.
+// Listing backward from a cursor fetches in reverse and flips the page afterwards.
+func (f MessageFilter) fetchOrder() MessageFilter {
+	if f.Cursor != nil && f.Cursor.Backward {
+		f.SortDesc = !f.SortDesc
+	}
+	return f
+}
+
+// newPage builds the page from messages fetched in fetchOrder, where one
+// message more than Limit tells that there are more to come
+func (f MessageFilter) newPage(messages []models.Message) MessagePage {
+	more := len(messages) > f.Limit
+	if more {
+		messages = messages[:f.Limit]
+	}
+
+	backward := f.Cursor != nil && f.Cursor.Backward
+	if backward {
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+	}
+
+	// Paging from a cursor, there is at least the cursor's message on the side we came from
+	var hasPrev, hasNext bool
+	switch {
+	case f.Cursor == nil:
+		hasPrev, hasNext = f.offset() > 0, more
+	case backward:
+		hasPrev, hasNext = more, true
+	default:
+		hasPrev, hasNext = true, more
+	}
+
+	page := MessagePage{Messages: messages}
+	if len(messages) > 0 {
+		if hasNext {
+			page.Next = f.cursorAt(messages[len(messages)-1], false)
+		}
+		if hasPrev {
+			page.Prev = f.cursorAt(messages[0], true)
+		}
+	}
+	return page
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/alper.meric/messaging-system/models"
@@ -8,24 +10,111 @@ import (
 
 // MessageRepository provides abstraction for message database operations
 type MessageRepository interface {
-	// Retrieves unsent messages
-	GetUnsentMessages(limit int) ([]models.Message, error)
+	// Claims messages that are due for sending, so no other worker takes them
+	// and they can no longer be changed through the API. Up to reserved of the
+	// limit go to the oldest high-priority messages, the rest of the batch is
+	// filled by priority and age with lower priorities before further
+	// high-priority messages.
+	ClaimUnsentMessages(ctx context.Context, limit, reserved int) ([]models.Message, error)
+
+	// Releases the claims of messages that were not processed after all
+	ReleaseMessages(ctx context.Context, ids []int) error
+
+	// Retrieves a message by its ID
+	GetMessage(ctx context.Context, id int) (models.Message, error)
+
+	// Retrieves a message by the ID the external service assigned to it
+	GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error)
+
+	// Retrieves the manual resends of a message, oldest first
+	GetResends(ctx context.Context, parentID int) ([]models.Message, error)
 
 	// Marks a message as sent
-	MarkMessageAsSent(id int, externalMsgID string) error
+	MarkMessageAsSent(ctx context.Context, id int, externalMsgID string) error
+
+	// Counts messages waiting to be sent
+	CountUnsentMessages(ctx context.Context) (int, error)
+
+	// Postpones a claimed message until the given time and releases the claim
+	DeferMessage(ctx context.Context, id int, until time.Time) error
+
+	// Records a failed delivery attempt and releases the claim, permanently failing the message if requested
+	RecordSendFailure(ctx context.Context, id int, reason string, permanent bool) error
+
+	// Cancels a message that has not been claimed for sending yet
+	CancelMessage(ctx context.Context, id int) error
+
+	// Changes a message that has not been claimed for sending yet
+	UpdateMessage(ctx context.Context, id int, update MessageUpdate) error
+
+	// Soft deletes a message that has not been sent or claimed for sending
+	DeleteMessage(ctx context.Context, id int) error
 
 	// Retrieves sent messages with pagination
-	GetSentMessages(page, limit int) ([]models.Message, int, error)
+	GetSentMessages(ctx context.Context, page, limit int) ([]models.Message, int, error)
+
+	// Retrieves a page of the messages matching a filter
+	ListMessages(ctx context.Context, filter MessageFilter) (MessagePage, error)
 
 	// Adds a new message
-	AddMessage(message models.Message) (int, error)
+	AddMessage(ctx context.Context, message models.Message) (int, error)
+}
+
+// ErrMessageNotFound is returned by MessageRepository implementations when no message matches a lookup
+var ErrMessageNotFound = errors.New("message not found")
+
+// ErrMessageNotEditable is returned when a message cannot be changed any more
+// because it has been claimed for sending, sent, failed or cancelled
+var ErrMessageNotEditable = errors.New("message can no longer be changed")
+
+// ErrMessageChanged is returned when a message no longer has the content an
+// update was based on because it was changed meanwhile
+var ErrMessageChanged = errors.New("message was changed meanwhile")
+
+// ClaimTimeout is how long a claim protects a message. An older claim is
+// considered abandoned by a worker that stopped in the middle of a batch.
+const ClaimTimeout = 10 * time.Minute
+
+// MessageUpdate holds the changes of UpdateMessage, nil fields are left unchanged
+type MessageUpdate struct {
+	Content       *string
+	PhoneNumber   *string
+	Category      *string
+	Priority      *int
+	CountryCode   *string // set along with PhoneNumber
+	Encoding      *string // set along with Content
+	Segments      *int
+	ScheduledAt   *time.Time
+	ClearSchedule bool // send as soon as possible instead of at ScheduledAt
+
+	// Transliteration is a language, models.MessageTransliterationOff, or
+	// empty for the default
+	Transliteration *string
+
+	// ExpectedContent and ExpectedTransliteration are what Encoding and
+	// Segments were derived from; when set, the update fails with
+	// ErrMessageChanged unless the message still has them
+	ExpectedContent         *string
+	ExpectedTransliteration *string
+}
+
+// ErrCacheMiss is returned by CacheRepository implementations when a message ID is not cached
+var ErrCacheMiss = errors.New("message ID not found in cache")
+
+// cacheTTL is how long a cached message ID is kept
+const cacheTTL = 24 * time.Hour
+
+// CachedMessage is what the cache knows about a message, keyed by its external ID
+type CachedMessage struct {
+	ID     int       `json:"id"` // 0 for entries written before the ID was cached
+	SentAt time.Time `json:"sentAt"`
 }
 
 // CacheRepository provides abstraction for message caching operations
 type CacheRepository interface {
-	// Caches message ID and send time
-	CacheMessageID(messageID string, sentAt time.Time) error
+	// Caches the ID and send time of a message under its external ID
+	CacheMessageID(ctx context.Context, externalID string, messageID int, sentAt time.Time) error
 
-	// Retrieves cached message information
-	GetCachedMessage(messageID string) (time.Time, error)
+	// Retrieves cached message information by external ID
+	GetCachedMessage(ctx context.Context, externalID string) (CachedMessage, error)
 }
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// NoopCacheRepository implements the CacheRepository interface without storing anything.
+// It is used when caching is disabled so callers never have to check for a nil cache.
+type NoopCacheRepository struct{}
+
+// NewNoopCacheRepository creates a new NoopCacheRepository instance
+func NewNoopCacheRepository() *NoopCacheRepository {
+	return &NoopCacheRepository{}
+}
+
+// CacheMessageID discards the entry
+func (NoopCacheRepository) CacheMessageID(ctx context.Context, externalID string, messageID int, sentAt time.Time) error {
+	return nil
+}
+
+// GetCachedMessage always reports a cache miss
+func (NoopCacheRepository) GetCachedMessage(ctx context.Context, externalID string) (CachedMessage, error) {
+	return CachedMessage{}, ErrCacheMiss
+}
@@ -0,0 +1,32 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/repository/repositorytest"
+)
+
+// TestPostgresRepositoryConformance runs against the database in
+// TEST_POSTGRES_DSN, e.g. "host=localhost user=postgres password=postgres dbname=messaging_test sslmode=disable".
+// The messages table of that database is emptied before every test.
+func TestPostgresRepositoryConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	repo, err := repository.NewPostgresRepositoryFromDSN(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to PostgreSQL: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	repositorytest.Run(t, func(t *testing.T) repository.MessageRepository {
+		if err := repo.GetDB().Exec("TRUNCATE TABLE messages RESTART IDENTITY").Error; err != nil {
+			t.Fatalf("failed to empty messages table: %v", err)
+		}
+		return repo
+	})
+}
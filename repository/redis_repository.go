@@ -2,45 +2,127 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/alper.meric/messaging-system/tracing"
 	"github.com/go-redis/redis/v8"
 )
 
-// RedisRepository implements the CacheRepository interface using Redis
+// RedisRepository implements the CacheRepository interface using Redis.
+// The client reconnects on its own, so a server that is down at startup or
+// goes away later only makes cache calls fail until it is reachable again.
 type RedisRepository struct {
-	client *redis.Client
-	ctx    context.Context
+	client    *redis.Client
+	metrics   metrics.Metrics
+	timeout   time.Duration
+	available atomic.Bool
 }
 
-// NewRedisRepository creates a new RedisRepository instance
-func NewRedisRepository(addr, password string, db int) (*RedisRepository, error) {
+// RedisOption configures optional RedisRepository dependencies
+type RedisOption func(*RedisRepository)
+
+// WithRedisMetrics sets the metrics recorder used for cache hit/miss counters
+func WithRedisMetrics(m metrics.Metrics) RedisOption {
+	return func(r *RedisRepository) {
+		r.metrics = m
+	}
+}
+
+// WithRedisTimeout sets the deadline applied to every Redis call, 0 disables it
+func WithRedisTimeout(d time.Duration) RedisOption {
+	return func(r *RedisRepository) {
+		r.timeout = d
+	}
+}
+
+// NewRedisRepository creates a new RedisRepository instance. An unreachable
+// server is logged but not treated as an error.
+func NewRedisRepository(addr, password string, db int, opts ...RedisOption) *RedisRepository {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
 		Password: password,
 		DB:       db,
 	})
 
-	ctx := context.Background()
+	r := &RedisRepository{
+		client:  client,
+		metrics: metrics.NewNoop(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
 
 	// Check connection
-	_, err := client.Ping(ctx).Result()
-	if err != nil {
-		return nil, fmt.Errorf("failed to ping redis server: %v", err)
+	if err := r.Ping(context.Background()); err != nil {
+		slog.Warn("redis is not reachable, cache calls will fail until it is", "addr", addr, logging.KeyError, err)
+	} else {
+		r.available.Store(true)
+	}
+
+	return r
+}
+
+// Ping checks that the Redis server is reachable
+func (r *RedisRepository) Ping(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.client.Ping(ctx).Err()
+	r.trackAvailability(err)
+	return err
+}
+
+// Available reports whether the last Redis call reached the server
+func (r *RedisRepository) Available() bool {
+	return r.available.Load()
+}
+
+// trackAvailability logs when the connection to Redis is lost or restored
+func (r *RedisRepository) trackAvailability(err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		// The caller giving up says nothing about the server
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if r.available.Swap(false) {
+			slog.Warn("redis connection lost", logging.KeyError, err)
+		}
+		return
 	}
 
-	return &RedisRepository{
-		client: client,
-		ctx:    ctx,
-	}, nil
+	if !r.available.Swap(true) {
+		slog.Info("redis connection restored")
+	}
+}
+
+// Close closes the Redis client and its connection pool
+func (r *RedisRepository) Close() error {
+	return r.client.Close()
 }
 
-// CacheMessageID saves a message ID and send time to Redis
-func (r *RedisRepository) CacheMessageID(messageID string, sentAt time.Time) error {
-	// Save message ID as key and send time as value
-	key := fmt.Sprintf("message:%s", messageID)
-	err := r.client.Set(r.ctx, key, sentAt.Format(time.RFC3339), 24*time.Hour).Err()
+// CacheMessageID saves a message's ID and send time to Redis under its external ID
+func (r *RedisRepository) CacheMessageID(ctx context.Context, externalID string, messageID int, sentAt time.Time) (err error) {
+	ctx, span := startSpan(ctx, "RedisRepository.CacheMessageID", dbSystemRedis)
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	value, err := json.Marshal(CachedMessage{ID: messageID, SentAt: sentAt})
+	if err != nil {
+		return fmt.Errorf("redis cache error: %v", err)
+	}
+
+	key := fmt.Sprintf("message:%s", externalID)
+	err = r.client.Set(ctx, key, value, cacheTTL).Err()
+	r.trackAvailability(err)
 	if err != nil {
 		return fmt.Errorf("redis cache error: %v", err)
 	}
@@ -48,21 +130,32 @@ func (r *RedisRepository) CacheMessageID(messageID string, sentAt time.Time) err
 }
 
 // GetCachedMessage retrieves message information from Redis
-func (r *RedisRepository) GetCachedMessage(messageID string) (time.Time, error) {
-	key := fmt.Sprintf("message:%s", messageID)
-	result, err := r.client.Get(r.ctx, key).Result()
+func (r *RedisRepository) GetCachedMessage(ctx context.Context, externalID string) (cached CachedMessage, err error) {
+	ctx, span := startSpan(ctx, "RedisRepository.GetCachedMessage", dbSystemRedis)
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := withTimeout(ctx, r.timeout)
+	defer cancel()
+
+	key := fmt.Sprintf("message:%s", externalID)
+	result, err := r.client.Get(ctx, key).Result()
+	r.trackAvailability(err)
 
 	if err == redis.Nil {
-		return time.Time{}, fmt.Errorf("message ID not found: %s", messageID)
+		r.metrics.CacheMiss()
+		return CachedMessage{}, fmt.Errorf("%w: %s", ErrCacheMiss, externalID)
 	} else if err != nil {
-		return time.Time{}, fmt.Errorf("redis read error: %v", err)
+		return CachedMessage{}, fmt.Errorf("redis read error: %v", err)
 	}
+	r.metrics.CacheHit()
 
-	// Parse time information
-	sentAt, err := time.Parse(time.RFC3339, result)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("time format error: %v", err)
+	// Entries written by earlier versions hold only the send time
+	if sentAt, err := time.Parse(time.RFC3339, result); err == nil {
+		return CachedMessage{SentAt: sentAt}, nil
+	}
+
+	if err := json.Unmarshal([]byte(result), &cached); err != nil {
+		return CachedMessage{}, fmt.Errorf("cached message format error: %v", err)
 	}
 
-	return sentAt, nil
+	return cached, nil
 }
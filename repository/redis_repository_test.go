@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alper.meric/messaging-system/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisRepository(t *testing.T) {
+	server := miniredis.RunT(t)
+	recorder := metrics.NewMemoryMetrics()
+	repo := NewRedisRepository(server.Addr(), "", 0, WithRedisMetrics(recorder), WithRedisTimeout(time.Second))
+	defer repo.Close()
+
+	ctx := context.Background()
+	sentAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.True(t, repo.Available())
+	assert.NoError(t, repo.CacheMessageID(ctx, "ext-1", 1, sentAt))
+	assert.Equal(t, cacheTTL, server.TTL("message:ext-1"))
+
+	got, err := repo.GetCachedMessage(ctx, "ext-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, got.ID)
+	assert.True(t, sentAt.Equal(got.SentAt))
+
+	// Entries cached before the message ID was stored only carry the send time
+	assert.NoError(t, server.Set("message:ext-old", sentAt.Format(time.RFC3339)))
+	got, err = repo.GetCachedMessage(ctx, "ext-old")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, got.ID)
+	assert.True(t, sentAt.Equal(got.SentAt))
+
+	_, err = repo.GetCachedMessage(ctx, "ext-2")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	assert.Equal(t, 2, recorder.Count(metrics.CacheHits))
+	assert.Equal(t, 1, recorder.Count(metrics.CacheMisses))
+}
+
+func TestRedisRepositoryReconnects(t *testing.T) {
+	server := miniredis.NewMiniRedis()
+	assert.NoError(t, server.Start())
+	addr := server.Addr()
+	server.Close()
+
+	// A server that is down at startup does not prevent creating the repository
+	repo := NewRedisRepository(addr, "", 0, WithRedisTimeout(time.Second))
+	defer repo.Close()
+
+	ctx := context.Background()
+	assert.False(t, repo.Available())
+	assert.Error(t, repo.CacheMessageID(ctx, "ext-1", 1, time.Now()))
+
+	// Once the server is back, calls succeed without recreating the repository
+	server = miniredis.NewMiniRedis()
+	assert.NoError(t, server.StartAddr(addr))
+	defer server.Close()
+
+	assert.Eventually(t, func() bool {
+		return repo.CacheMessageID(ctx, "ext-1", 1, time.Now()) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.True(t, repo.Available())
+
+	// Losing the server again is noticed as well
+	server.Close()
+	assert.Error(t, repo.Ping(ctx))
+	assert.False(t, repo.Available())
+}
@@ -0,0 +1,890 @@
+// Package repositorytest provides the conformance suite every
+// repository.MessageRepository implementation must pass.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/stretchr/testify/suite"
+)
+
+// MessageRepositorySuite checks the behaviour shared by all MessageRepository implementations
+type MessageRepositorySuite struct {
+	suite.Suite
+
+	// NewRepository returns an empty repository, called before every test
+	NewRepository func(t *testing.T) repository.MessageRepository
+
+	repo repository.MessageRepository
+	ctx  context.Context
+}
+
+// Run runs the conformance suite against the repositories returned by newRepository
+func Run(t *testing.T, newRepository func(t *testing.T) repository.MessageRepository) {
+	suite.Run(t, &MessageRepositorySuite{NewRepository: newRepository})
+}
+
+// SetupTest creates a fresh repository for each test
+func (s *MessageRepositorySuite) SetupTest() {
+	s.repo = s.NewRepository(s.T())
+	s.ctx = context.Background()
+}
+
+// add stores a message and returns its ID. Messages added one after another
+// get distinct creation times so their order is well defined.
+func (s *MessageRepositorySuite) add(content string) int {
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     content,
+	})
+	s.Require().NoError(err)
+	time.Sleep(2 * time.Millisecond)
+	return id
+}
+
+// addWithPriority stores a message with the given priority and returns its ID
+func (s *MessageRepositorySuite) addWithPriority(content string, priority int) int {
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     content,
+		Priority:    priority,
+	})
+	s.Require().NoError(err)
+	time.Sleep(2 * time.Millisecond)
+	return id
+}
+
+// markSent marks a message as sent, keeping send times distinct
+func (s *MessageRepositorySuite) markSent(id int) {
+	s.Require().NoError(s.repo.MarkMessageAsSent(s.ctx, id, "ext-"+time.Now().Format(time.RFC3339Nano)))
+	time.Sleep(2 * time.Millisecond)
+}
+
+// claimAll claims every message that is due, in the order they are sent
+func (s *MessageRepositorySuite) claimAll() []models.Message {
+	messages, err := s.repo.ClaimUnsentMessages(s.ctx, 100, 100)
+	s.Require().NoError(err)
+	return messages
+}
+
+// ids returns the IDs of messages in order
+func ids(messages []models.Message) []int {
+	result := make([]int, len(messages))
+	for i, m := range messages {
+		result[i] = m.ID
+	}
+	return result
+}
+
+func (s *MessageRepositorySuite) TestAddMessage() {
+	before := time.Now().Add(-time.Second)
+
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     "hello",
+		IsSent:      true,
+	})
+	s.Require().NoError(err)
+	s.NotZero(id)
+
+	other := s.add("second")
+	s.NotEqual(id, other, "IDs must be unique")
+
+	messages := s.claimAll()
+	s.Require().Len(messages, 2)
+
+	// New messages always start out unsent
+	first := messages[0]
+	s.Equal(id, first.ID)
+	s.Equal("hello", first.Content)
+	s.Equal("+905551112233", first.PhoneNumber)
+	s.False(first.IsSent)
+	s.True(first.CreatedAt.After(before), "creation time must be set")
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessagesOrderAndLimit() {
+	first := s.add("first")
+	second := s.add("second")
+	third := s.add("third")
+	fourth := s.add("fourth")
+	s.markSent(second)
+
+	messages, err := s.repo.ClaimUnsentMessages(s.ctx, 2, 0)
+	s.Require().NoError(err)
+	s.Equal([]int{first, third}, ids(messages), "unsent messages come oldest first")
+
+	s.Equal([]int{fourth}, ids(s.claimAll()))
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessagesEmpty() {
+	s.Empty(s.claimAll())
+}
+
+func (s *MessageRepositorySuite) TestCountUnsentMessages() {
+	count, err := s.repo.CountUnsentMessages(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(0, count)
+
+	first := s.add("first")
+	s.add("second")
+	s.markSent(first)
+
+	count, err = s.repo.CountUnsentMessages(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+}
+
+func (s *MessageRepositorySuite) TestMarkMessageAsSent() {
+	id := s.add("hello")
+	before := time.Now().Add(-time.Second)
+
+	s.Require().NoError(s.repo.MarkMessageAsSent(s.ctx, id, "ext-1"))
+
+	messages, total, err := s.repo.GetSentMessages(s.ctx, 1, 10)
+	s.Require().NoError(err)
+	s.Equal(1, total)
+	s.Require().Len(messages, 1)
+	s.Equal(id, messages[0].ID)
+	s.True(messages[0].IsSent)
+	s.Equal("ext-1", messages[0].ExternalMsgID)
+	s.True(messages[0].SentAt.After(before), "send time must be set")
+
+	s.Empty(s.claimAll())
+}
+
+func (s *MessageRepositorySuite) TestGetMessage() {
+	id := s.add("hello")
+	s.add("other")
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(id, message.ID)
+	s.Equal("hello", message.Content)
+	s.Equal(models.MessageStatusUnsent, message.Status())
+
+	_, err = s.repo.GetMessage(s.ctx, 999999)
+	s.ErrorIs(err, repository.ErrMessageNotFound)
+}
+
+func (s *MessageRepositorySuite) TestGetMessageByExternalID() {
+	id := s.add("hello")
+	s.add("other")
+	s.Require().NoError(s.repo.MarkMessageAsSent(s.ctx, id, "ext-1"))
+
+	message, err := s.repo.GetMessageByExternalID(s.ctx, "ext-1")
+	s.Require().NoError(err)
+	s.Equal(id, message.ID)
+	s.Equal("ext-1", message.ExternalMsgID)
+	s.True(message.IsSent)
+
+	_, err = s.repo.GetMessageByExternalID(s.ctx, "ext-2")
+	s.ErrorIs(err, repository.ErrMessageNotFound)
+
+	// Unsent messages have no external ID to be found by
+	_, err = s.repo.GetMessageByExternalID(s.ctx, "")
+	s.ErrorIs(err, repository.ErrMessageNotFound)
+}
+
+func (s *MessageRepositorySuite) TestMarkMessageAsSentUnknownID() {
+	s.Error(s.repo.MarkMessageAsSent(s.ctx, 999999, "ext-1"))
+}
+
+func (s *MessageRepositorySuite) TestGetSentMessagesPagination() {
+	var sent []int
+	for i := 0; i < 5; i++ {
+		sent = append(sent, s.add("message"))
+	}
+	s.add("unsent")
+	for _, id := range sent {
+		s.markSent(id)
+	}
+
+	page1, total, err := s.repo.GetSentMessages(s.ctx, 1, 2)
+	s.Require().NoError(err)
+	s.Equal(5, total)
+	s.Equal([]int{sent[4], sent[3]}, ids(page1), "sent messages come most recently sent first")
+
+	page3, total, err := s.repo.GetSentMessages(s.ctx, 3, 2)
+	s.Require().NoError(err)
+	s.Equal(5, total)
+	s.Equal([]int{sent[0]}, ids(page3))
+
+	page4, total, err := s.repo.GetSentMessages(s.ctx, 4, 2)
+	s.Require().NoError(err)
+	s.Equal(5, total)
+	s.Empty(page4)
+}
+
+func (s *MessageRepositorySuite) TestRecordSendFailure() {
+	transient := s.add("transient")
+	permanent := s.add("permanent")
+
+	s.Require().NoError(s.repo.RecordSendFailure(s.ctx, transient, "timeout", false))
+	s.Require().NoError(s.repo.RecordSendFailure(s.ctx, permanent, "rejected", true))
+
+	// Transient failures are retried, permanent ones leave the queue
+	unsent := s.claimAll()
+	s.Require().Equal([]int{transient}, ids(unsent))
+	s.Equal(1, unsent[0].Attempts)
+	s.Equal("timeout", unsent[0].LastError)
+	s.Nil(unsent[0].FailedAt)
+
+	count, err := s.repo.CountUnsentMessages(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(1, count)
+
+	page, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Status: models.MessageStatusFailed, Page: 1, Limit: 10})
+	s.Require().NoError(err)
+	s.Equal(1, *page.Total)
+	failed := page.Messages
+	s.Require().Equal([]int{permanent}, ids(failed))
+	s.Equal("rejected", failed[0].LastError)
+	s.NotNil(failed[0].FailedAt)
+	s.Equal(models.MessageStatusFailed, failed[0].Status())
+
+	// A successful send counts as an attempt too
+	s.markSent(transient)
+	sent, _, err := s.repo.GetSentMessages(s.ctx, 1, 10)
+	s.Require().NoError(err)
+	s.Require().Len(sent, 1)
+	s.Equal(2, sent[0].Attempts)
+
+	s.Error(s.repo.RecordSendFailure(s.ctx, transient, "already sent", false))
+	s.Error(s.repo.RecordSendFailure(s.ctx, 999999, "unknown", false))
+}
+
+// list runs ListMessages with the first page of ten and returns the IDs found
+func (s *MessageRepositorySuite) list(filter repository.MessageFilter) []int {
+	filter.Page, filter.Limit = 1, 10
+	page, err := s.repo.ListMessages(s.ctx, filter)
+	s.Require().NoError(err)
+	s.Require().NotNil(page.Total)
+	s.Equal(len(page.Messages), *page.Total)
+	return ids(page.Messages)
+}
+
+func (s *MessageRepositorySuite) TestListMessagesFilters() {
+	add := func(phone, content string) int {
+		id, err := s.repo.AddMessage(s.ctx, models.Message{PhoneNumber: phone, Content: content})
+		s.Require().NoError(err)
+		time.Sleep(2 * time.Millisecond)
+		return id
+	}
+
+	first := add("+905551112233", "Your code is 1234")
+	middle := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	second := add("+905559998877", "Weekend SALE: 50% off")
+	third := add("+4915112345678", "100_000 points")
+	failed := add("+905551112233", "rejected")
+
+	s.Require().NoError(s.repo.MarkMessageAsSent(s.ctx, second, "ext-second"))
+	s.Require().NoError(s.repo.RecordSendFailure(s.ctx, failed, "rejected", true))
+
+	s.Equal([]int{first, second, third, failed}, s.list(repository.MessageFilter{}))
+
+	s.Equal([]int{second}, s.list(repository.MessageFilter{Status: models.MessageStatusSent}))
+	s.Equal([]int{first, third}, s.list(repository.MessageFilter{Status: models.MessageStatusUnsent}))
+	s.Equal([]int{failed}, s.list(repository.MessageFilter{Status: models.MessageStatusFailed}))
+
+	s.Equal([]int{first, failed}, s.list(repository.MessageFilter{PhoneNumber: "+905551112233"}))
+	s.Empty(s.list(repository.MessageFilter{PhoneNumber: "+90555"}), "phone number must match exactly")
+	s.Equal([]int{first, second, failed}, s.list(repository.MessageFilter{PhonePrefix: "+90555"}))
+
+	s.Equal([]int{second}, s.list(repository.MessageFilter{Content: "sale"}), "content search ignores case")
+	s.Equal([]int{second}, s.list(repository.MessageFilter{Content: "50%"}))
+	s.Empty(s.list(repository.MessageFilter{Content: "5%o"}), "wildcards must be escaped")
+	s.Equal([]int{third}, s.list(repository.MessageFilter{Content: "0_0"}))
+
+	s.Equal([]int{second}, s.list(repository.MessageFilter{ExternalMsgID: "ext-second"}))
+
+	s.Equal([]int{first}, s.list(repository.MessageFilter{CreatedTo: &middle}))
+	s.Equal([]int{second, third, failed}, s.list(repository.MessageFilter{CreatedFrom: &middle}))
+
+	sentFrom := middle
+	s.Equal([]int{second}, s.list(repository.MessageFilter{SentFrom: &sentFrom}), "unsent messages have no send time")
+	s.Empty(s.list(repository.MessageFilter{SentTo: &middle}))
+
+	// Conditions are combined
+	s.Equal([]int{failed}, s.list(repository.MessageFilter{
+		Status:      models.MessageStatusFailed,
+		PhoneNumber: "+905551112233",
+	}))
+}
+
+func (s *MessageRepositorySuite) TestListMessagesSorting() {
+	first := s.add("first")
+	second := s.add("second")
+	third := s.add("third")
+	s.markSent(third)
+	s.markSent(first)
+
+	s.Equal([]int{first, second, third}, s.list(repository.MessageFilter{SortBy: repository.SortByCreatedAt}))
+	s.Equal([]int{third, second, first}, s.list(repository.MessageFilter{SortBy: repository.SortByCreatedAt, SortDesc: true}))
+	s.Equal([]int{third, second, first}, s.list(repository.MessageFilter{SortBy: repository.SortByID, SortDesc: true}))
+
+	// Unsent messages sort as if sent after all others
+	s.Equal([]int{third, first, second}, s.list(repository.MessageFilter{SortBy: repository.SortBySentAt}))
+	s.Equal([]int{second, first, third}, s.list(repository.MessageFilter{SortBy: repository.SortBySentAt, SortDesc: true}))
+}
+
+func (s *MessageRepositorySuite) TestListMessagesPagination() {
+	var added []int
+	for i := 0; i < 5; i++ {
+		added = append(added, s.add("message"))
+	}
+
+	page2, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Page: 2, Limit: 2})
+	s.Require().NoError(err)
+	s.Equal(5, *page2.Total)
+	s.False(page2.TotalEstimated)
+	s.Equal([]int{added[2], added[3]}, ids(page2.Messages))
+
+	// Page mode hands out cursors too, so clients can switch to keyset paging
+	s.Require().NotNil(page2.Next)
+	s.Equal(added[3], page2.Next.ID)
+	s.Require().NotNil(page2.Prev)
+	s.Equal(added[2], page2.Prev.ID)
+
+	page1, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Page: 1, Limit: 2})
+	s.Require().NoError(err)
+	s.Nil(page1.Prev)
+
+	page3, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Page: 3, Limit: 2})
+	s.Require().NoError(err)
+	s.Equal([]int{added[4]}, ids(page3.Messages))
+	s.Nil(page3.Next)
+
+	none, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Page: 1, Limit: 2, Count: repository.CountNone})
+	s.Require().NoError(err)
+	s.Nil(none.Total)
+	s.Len(none.Messages, 2)
+
+	estimated, err := s.repo.ListMessages(s.ctx, repository.MessageFilter{Page: 1, Limit: 2, Count: repository.CountEstimated})
+	s.Require().NoError(err)
+	s.NotNil(estimated.Total)
+}
+
+// walk pages through the whole listing of filter with cursors, forward and
+// then backward from the last page, and returns the IDs seen in both directions
+func (s *MessageRepositorySuite) walk(filter repository.MessageFilter) (forward, backward []int) {
+	filter.Page, filter.Limit, filter.Count = 1, 2, repository.CountNone
+
+	var last repository.MessagePage
+	for pages := 0; ; pages++ {
+		s.Require().Less(pages, 10, "paging must end")
+		page, err := s.repo.ListMessages(s.ctx, filter)
+		s.Require().NoError(err)
+		forward = append(forward, ids(page.Messages)...)
+		last = page
+		if page.Next == nil {
+			break
+		}
+		filter.Cursor = page.Next
+	}
+
+	backward = ids(last.Messages)
+	for pages := 0; last.Prev != nil; pages++ {
+		s.Require().Less(pages, 10, "paging must end")
+		filter.Cursor = last.Prev
+		page, err := s.repo.ListMessages(s.ctx, filter)
+		s.Require().NoError(err)
+		backward = append(ids(page.Messages), backward...)
+		last = page
+	}
+
+	return forward, backward
+}
+
+func (s *MessageRepositorySuite) TestListMessagesCursor() {
+	var added []int
+	for i := 0; i < 5; i++ {
+		added = append(added, s.add("message"))
+	}
+	s.markSent(added[3])
+	s.markSent(added[1])
+
+	for _, filter := range []repository.MessageFilter{
+		{SortBy: repository.SortByCreatedAt, SortDesc: true},
+		{SortBy: repository.SortByCreatedAt},
+		{SortBy: repository.SortByID},
+		{SortBy: repository.SortByID, SortDesc: true},
+		{SortBy: repository.SortBySentAt, SortDesc: true},
+		{SortBy: repository.SortBySentAt},
+		{Status: models.MessageStatusSent, SortBy: repository.SortBySentAt, SortDesc: true},
+	} {
+		expected := s.list(filter)
+		forward, backward := s.walk(filter)
+		s.Equal(expected, forward, "forward %+v", filter)
+		s.Equal(expected, backward, "backward %+v", filter)
+	}
+}
+
+func (s *MessageRepositorySuite) TestListMessagesCursorIsStable() {
+	first := s.add("first")
+	second := s.add("second")
+	third := s.add("third")
+
+	filter := repository.MessageFilter{SortBy: repository.SortByCreatedAt, SortDesc: true, Page: 1, Limit: 2}
+	page1, err := s.repo.ListMessages(s.ctx, filter)
+	s.Require().NoError(err)
+	s.Equal([]int{third, second}, ids(page1.Messages))
+
+	// A message arriving meanwhile would shift an offset by one, not a cursor
+	newest := s.add("newest")
+
+	filter.Cursor = page1.Next
+	page2, err := s.repo.ListMessages(s.ctx, filter)
+	s.Require().NoError(err)
+	s.Equal([]int{first}, ids(page2.Messages))
+	s.Nil(page2.Next)
+
+	// Paging back lands on the old first page and then on the new message
+	filter.Cursor = page2.Prev
+	back, err := s.repo.ListMessages(s.ctx, filter)
+	s.Require().NoError(err)
+	s.Equal([]int{third, second}, ids(back.Messages))
+	s.Require().NotNil(back.Prev)
+
+	filter.Cursor = back.Prev
+	newer, err := s.repo.ListMessages(s.ctx, filter)
+	s.Require().NoError(err)
+	s.Equal([]int{newest}, ids(newer.Messages))
+	s.Nil(newer.Prev)
+	s.NotNil(newer.Next)
+
+	// Cursors survive their string form
+	decoded, err := repository.DecodeCursor(page1.Next.Encode())
+	s.Require().NoError(err)
+	s.True(page1.Next.Time.Equal(*decoded.Time))
+	decoded.Time = page1.Next.Time
+	s.Equal(page1.Next, decoded)
+}
+
+func (s *MessageRepositorySuite) TestListMessagesInvalidFilter() {
+	now := time.Now()
+	for _, filter := range []repository.MessageFilter{
+		{Status: "pending", Page: 1, Limit: 10},
+		{SortBy: "content", Page: 1, Limit: 10},
+		{CreatedFrom: &now, CreatedTo: &now, Page: 1, Limit: 10},
+		{Page: 0, Limit: 10},
+		{Page: 1, Limit: 10, Count: "approximate"},
+		{Cursor: &repository.Cursor{SortBy: repository.SortByID, ID: 1}, Limit: 10},
+		{Cursor: &repository.Cursor{SortBy: repository.SortByCreatedAt, SortDesc: true, ID: 1}, SortDesc: true, Limit: 10},
+	} {
+		_, err := s.repo.ListMessages(s.ctx, filter)
+		s.Error(err, "%+v", filter)
+	}
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessages() {
+	first := s.add("first")
+	second := s.add("second")
+	third := s.add("third")
+	cancelled := s.add("cancelled")
+	s.Require().NoError(s.repo.CancelMessage(s.ctx, cancelled))
+
+	claimed, err := s.repo.ClaimUnsentMessages(s.ctx, 2, 0)
+	s.Require().NoError(err)
+	s.Require().Equal([]int{first, second}, ids(claimed), "the oldest messages are claimed first")
+	s.NotNil(claimed[0].ClaimedAt)
+
+	// Claimed messages are neither claimed again nor listed as next in line
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Equal([]int{third}, ids(claimed))
+
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Empty(claimed)
+
+	// They are still waiting to be sent, unlike the cancelled one
+	count, err := s.repo.CountUnsentMessages(s.ctx)
+	s.Require().NoError(err)
+	s.Equal(3, count)
+
+	// Released and transiently failed messages can be claimed again
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, []int{first}))
+	s.Require().NoError(s.repo.RecordSendFailure(s.ctx, second, "timeout", false))
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, nil))
+
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Equal([]int{first, second}, ids(claimed))
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessagesConcurrently() {
+	for i := 0; i < 20; i++ {
+		_, err := s.repo.AddMessage(s.ctx, models.Message{PhoneNumber: "+905551112233", Content: "message"})
+		s.Require().NoError(err)
+	}
+
+	var (
+		mutex   sync.Mutex
+		wg      sync.WaitGroup
+		claimed []int
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 3; j++ {
+				messages, err := s.repo.ClaimUnsentMessages(s.ctx, 2, 0)
+				s.NoError(err)
+				mutex.Lock()
+				claimed = append(claimed, ids(messages)...)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every message is claimed exactly once
+	s.Len(claimed, 20)
+	seen := make(map[int]bool)
+	for _, id := range claimed {
+		s.False(seen[id], "message %d claimed twice", id)
+		seen[id] = true
+	}
+}
+
+func (s *MessageRepositorySuite) TestScheduledMessages() {
+	later := time.Now().Add(time.Hour)
+	scheduled, err := s.repo.AddMessage(s.ctx, models.Message{PhoneNumber: "+905551112233", Content: "later", ScheduledAt: &later})
+	s.Require().NoError(err)
+	now := s.add("now")
+
+	claimed, err := s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Equal([]int{now}, ids(claimed), "messages are not sent before their scheduled time")
+
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, scheduled, repository.MessageUpdate{ClearSchedule: true}))
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Equal([]int{scheduled}, ids(claimed))
+}
+
+func (s *MessageRepositorySuite) TestCancelMessage() {
+	id := s.add("hello")
+
+	s.Require().NoError(s.repo.CancelMessage(s.ctx, id))
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(models.MessageStatusCancelled, message.Status())
+	s.NotNil(message.CancelledAt)
+	s.Equal([]int{id}, s.list(repository.MessageFilter{Status: models.MessageStatusCancelled}))
+	s.Empty(s.list(repository.MessageFilter{Status: models.MessageStatusUnsent}))
+
+	s.ErrorIs(s.repo.CancelMessage(s.ctx, id), repository.ErrMessageNotEditable, "cancelling twice")
+	s.ErrorIs(s.repo.CancelMessage(s.ctx, 999999), repository.ErrMessageNotFound)
+}
+
+func (s *MessageRepositorySuite) TestUpdateMessage() {
+	id := s.add("hello")
+	content, phone := "changed", "+905559998877"
+	scheduledAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{
+		Content:     &content,
+		PhoneNumber: &phone,
+		ScheduledAt: &scheduledAt,
+	}))
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(content, message.Content)
+	s.Equal(phone, message.PhoneNumber)
+	s.Require().NotNil(message.ScheduledAt)
+	s.True(scheduledAt.Equal(*message.ScheduledAt))
+
+	// Fields that are not given stay as they are
+	other := "again"
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Content: &other}))
+	message, err = s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(other, message.Content)
+	s.Equal(phone, message.PhoneNumber)
+	s.NotNil(message.ScheduledAt)
+
+	s.Error(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{}))
+	s.ErrorIs(s.repo.UpdateMessage(s.ctx, 999999, repository.MessageUpdate{Content: &content}), repository.ErrMessageNotFound)
+}
+
+func (s *MessageRepositorySuite) TestUpdateMessageExpectedContent() {
+	id := s.add("hello")
+	content, encoding, tr, none := "changed", "UCS-2", "tr", ""
+
+	// The update is based on content the message no longer has
+	stale := "stale"
+	err := s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{
+		Transliteration: &tr, Encoding: &encoding, ExpectedContent: &stale, ExpectedTransliteration: &none,
+	})
+	s.ErrorIs(err, repository.ErrMessageChanged)
+
+	hello := "hello"
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{
+		Transliteration: &tr, ExpectedContent: &hello, ExpectedTransliteration: &none,
+	}))
+
+	// The transliteration changed meanwhile, the content update must be recomputed
+	err = s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{
+		Content: &content, Encoding: &encoding, ExpectedContent: &hello, ExpectedTransliteration: &none,
+	})
+	s.ErrorIs(err, repository.ErrMessageChanged)
+
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{
+		Content: &content, Encoding: &encoding, ExpectedContent: &hello, ExpectedTransliteration: &tr,
+	}))
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(content, message.Content)
+	s.Equal(encoding, message.Encoding)
+
+	// A claimed message is not editable, whatever it contains
+	_, err = s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	err = s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Content: &hello, ExpectedContent: &stale})
+	s.ErrorIs(err, repository.ErrMessageNotEditable)
+}
+
+func (s *MessageRepositorySuite) TestDeleteMessage() {
+	id := s.add("hello")
+	cancelled := s.add("cancelled")
+	s.Require().NoError(s.repo.CancelMessage(s.ctx, cancelled))
+
+	s.Require().NoError(s.repo.DeleteMessage(s.ctx, id))
+	s.Require().NoError(s.repo.DeleteMessage(s.ctx, cancelled))
+
+	_, err := s.repo.GetMessage(s.ctx, id)
+	s.ErrorIs(err, repository.ErrMessageNotFound)
+	s.Empty(s.list(repository.MessageFilter{}))
+	s.Empty(s.claimAll())
+
+	s.ErrorIs(s.repo.DeleteMessage(s.ctx, id), repository.ErrMessageNotFound, "deleting twice")
+}
+
+func (s *MessageRepositorySuite) TestChangesRequireUnclaimedMessage() {
+	claimedID := s.add("claimed")
+	sentID := s.add("sent")
+	s.Require().NoError(s.repo.MarkMessageAsSent(s.ctx, sentID, "ext-1"))
+	_, err := s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+
+	content := "changed"
+	for _, id := range []int{claimedID, sentID} {
+		s.ErrorIs(s.repo.CancelMessage(s.ctx, id), repository.ErrMessageNotEditable)
+		s.ErrorIs(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Content: &content}), repository.ErrMessageNotEditable)
+		s.ErrorIs(s.repo.DeleteMessage(s.ctx, id), repository.ErrMessageNotEditable)
+	}
+
+	message, err := s.repo.GetMessage(s.ctx, claimedID)
+	s.Require().NoError(err)
+	s.Equal("claimed", message.Content)
+	s.Nil(message.CancelledAt)
+
+	// Once released, the message can be changed again
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, []int{claimedID}))
+	s.NoError(s.repo.UpdateMessage(s.ctx, claimedID, repository.MessageUpdate{Content: &content}))
+}
+
+func (s *MessageRepositorySuite) TestGetResends() {
+	original := s.add("original")
+	s.add("other")
+	var resends []int
+	for i := 0; i < 2; i++ {
+		id, err := s.repo.AddMessage(s.ctx, models.Message{PhoneNumber: "+905551112233", Content: "original", ParentID: &original})
+		s.Require().NoError(err)
+		resends = append(resends, id)
+		time.Sleep(time.Millisecond)
+	}
+
+	messages, err := s.repo.GetResends(s.ctx, original)
+	s.Require().NoError(err)
+	s.Equal(resends, ids(messages), "oldest first")
+	s.Equal(&original, messages[0].ParentID)
+
+	message, err := s.repo.GetMessage(s.ctx, resends[0])
+	s.Require().NoError(err)
+	s.Equal(&original, message.ParentID)
+
+	messages, err = s.repo.GetResends(s.ctx, resends[0])
+	s.Require().NoError(err)
+	s.Empty(messages)
+}
+
+func (s *MessageRepositorySuite) TestDeferMessage() {
+	id := s.add("promotion")
+	_, err := s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	s.Require().NoError(s.repo.DeferMessage(s.ctx, id, until))
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Nil(message.ClaimedAt)
+	s.Require().NotNil(message.ScheduledAt)
+	s.True(until.Equal(*message.ScheduledAt))
+
+	// Deferred messages wait for their time but can be changed meanwhile
+	claimed, err := s.repo.ClaimUnsentMessages(s.ctx, 10, 0)
+	s.Require().NoError(err)
+	s.Empty(claimed)
+	s.NoError(s.repo.CancelMessage(s.ctx, id))
+
+	s.Error(s.repo.DeferMessage(s.ctx, 999999, until))
+}
+
+func (s *MessageRepositorySuite) TestMessageCategory() {
+	id := s.add("receipt")
+	promotional, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     "discount",
+		Category:    models.MessageCategoryPromotional,
+	})
+	s.Require().NoError(err)
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(models.MessageCategoryTransactional, message.Category, "messages are transactional by default")
+
+	message, err = s.repo.GetMessage(s.ctx, promotional)
+	s.Require().NoError(err)
+	s.Equal(models.MessageCategoryPromotional, message.Category)
+
+	category := models.MessageCategoryPromotional
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Category: &category}))
+	message, err = s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(models.MessageCategoryPromotional, message.Category)
+}
+
+func (s *MessageRepositorySuite) TestCountryCode() {
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     "hello",
+		CountryCode: "TR",
+	})
+	s.Require().NoError(err)
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal("TR", message.CountryCode)
+
+	phoneNumber, country := "+442079460958", "GB"
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{PhoneNumber: &phoneNumber, CountryCode: &country}))
+	message, err = s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(phoneNumber, message.PhoneNumber)
+	s.Equal(country, message.CountryCode)
+
+	// Messages added without it have no country
+	message, err = s.repo.GetMessage(s.ctx, s.add("unknown"))
+	s.Require().NoError(err)
+	s.Empty(message.CountryCode)
+}
+
+func (s *MessageRepositorySuite) TestContentEncoding() {
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber: "+905551112233",
+		Content:     "teşekkürler",
+		Encoding:    "UCS-2",
+		Segments:    1,
+	})
+	s.Require().NoError(err)
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal("UCS-2", message.Encoding)
+	s.Equal(1, message.Segments)
+
+	content, encoding, segments := "thanks", "GSM-7", 1
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Content: &content, Encoding: &encoding, Segments: &segments}))
+	message, err = s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal("GSM-7", message.Encoding)
+
+	// Messages added without them, e.g. directly in the database, have neither
+	message, err = s.repo.GetMessage(s.ctx, s.add("unknown"))
+	s.Require().NoError(err)
+	s.Empty(message.Encoding)
+	s.Zero(message.Segments)
+}
+
+func (s *MessageRepositorySuite) TestTransliteration() {
+	id, err := s.repo.AddMessage(s.ctx, models.Message{
+		PhoneNumber:     "+905551112233",
+		Content:         "teşekkürler",
+		Transliteration: "tr",
+	})
+	s.Require().NoError(err)
+
+	message, err := s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal("tr", message.Transliteration)
+	s.Equal("teşekkürler", message.Content, "content is stored as written")
+
+	off := models.MessageTransliterationOff
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, id, repository.MessageUpdate{Transliteration: &off}))
+	message, err = s.repo.GetMessage(s.ctx, id)
+	s.Require().NoError(err)
+	s.Equal(off, message.Transliteration)
+
+	// Messages added without it use the default
+	message, err = s.repo.GetMessage(s.ctx, s.add("default"))
+	s.Require().NoError(err)
+	s.Empty(message.Transliteration)
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessagesByPriority() {
+	low := s.addWithPriority("newsletter", models.MessagePriorityLow)
+	normal := s.add("receipt")
+	high := s.addWithPriority("otp", models.MessagePriorityHigh)
+	later := s.addWithPriority("otp again", models.MessagePriorityHigh)
+
+	messages := s.claimAll()
+	s.Equal([]int{high, later, normal, low}, ids(messages), "higher priorities come first, then older messages")
+
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, ids(messages)))
+	priority := models.MessagePriorityHigh
+	s.Require().NoError(s.repo.UpdateMessage(s.ctx, low, repository.MessageUpdate{Priority: &priority}))
+	s.Equal([]int{low, high, later, normal}, ids(s.claimAll()))
+}
+
+func (s *MessageRepositorySuite) TestClaimUnsentMessagesReservesHighPriorityShare() {
+	var high []int
+	for i := 0; i < 4; i++ {
+		high = append(high, s.addWithPriority(fmt.Sprintf("otp %d", i), models.MessagePriorityHigh))
+	}
+	low := s.addWithPriority("newsletter", models.MessagePriorityLow)
+	normal := s.add("receipt")
+
+	// Two of three slots are reserved, the third goes to the lower priorities
+	// even though more high-priority messages are waiting
+	claimed, err := s.repo.ClaimUnsentMessages(s.ctx, 3, 2)
+	s.Require().NoError(err)
+	s.Equal([]int{high[0], high[1], normal}, ids(claimed), "claimed messages come in the order they are sent")
+
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 3, 2)
+	s.Require().NoError(err)
+	s.Equal([]int{high[2], high[3], low}, ids(claimed))
+
+	// Without lower priorities waiting, high-priority messages fill the batch
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, []int{high[0], high[1], high[2], high[3]}))
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 3, 1)
+	s.Require().NoError(err)
+	s.Equal(high[:3], ids(claimed))
+
+	// Reserving the whole batch claims strictly by priority
+	s.Require().NoError(s.repo.ReleaseMessages(s.ctx, append(ids(claimed), normal, low)))
+	claimed, err = s.repo.ClaimUnsentMessages(s.ctx, 6, 6)
+	s.Require().NoError(err)
+	s.Equal([]int{high[0], high[1], high[2], high[3], normal, low}, ids(claimed))
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"log/slog"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// SQLiteRepository implements the MessageRepository interface using an SQLite
+// database file. The driver is pure Go, so no C toolchain is needed.
+type SQLiteRepository struct {
+	*GormRepository
+}
+
+// sqlitePragmas make concurrent access wait for locks instead of failing and
+// let readers proceed while a write is in progress
+const sqlitePragmas = "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+
+// NewSQLiteRepository creates a new SQLiteRepository instance for the database
+// at path, which is created if it does not exist. ":memory:" opens a private
+// in-memory database.
+func NewSQLiteRepository(path string, opts ...GormOption) (*SQLiteRepository, error) {
+	dsn := path
+	if strings.Contains(dsn, "?") {
+		dsn += "&" + sqlitePragmas
+	} else {
+		dsn += "?" + sqlitePragmas
+	}
+
+	// SQLite allows a single writer; one connection serialises writes inside
+	// the process and keeps ":memory:" databases from being opened twice
+	r, err := openGormRepository(sqlite.Open(dsn), "SQLiteRepository", dbSystemSQLite, func(sqlDB *sql.DB) {
+		sqlDB.SetMaxOpenConns(1)
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("database connection established", "path", path)
+	return &SQLiteRepository{GormRepository: r}, nil
+}
+
+// dbSystemSQLite is the database system attribute of SQLite spans
+var dbSystemSQLite = semconv.DBSystemSqlite
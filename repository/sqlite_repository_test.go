@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.MessageRepository {
+		repo, err := repository.NewSQLiteRepository(filepath.Join(t.TempDir(), "messages.db"))
+		if err != nil {
+			t.Fatalf("failed to open SQLite database: %v", err)
+		}
+		t.Cleanup(func() { _ = repo.Close() })
+		return repo
+	})
+}
+
+func TestSQLiteRepositoryPersists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "messages.db")
+
+	repo, err := repository.NewSQLiteRepository(path)
+	assert.NoError(t, err)
+	id, err := repo.AddMessage(ctx, models.Message{PhoneNumber: "+905551112233", Content: "hello"})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	// Reopening runs the migration again on the existing schema
+	repo, err = repository.NewSQLiteRepository(path)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	messages, err := repo.ClaimUnsentMessages(ctx, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, id, messages[0].ID)
+	assert.NoError(t, repo.Ping(ctx))
+}
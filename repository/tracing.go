@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/alper.meric/messaging-system/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a client span for a repository call against the given
+// database system
+func startSpan(ctx context.Context, name string, system attribute.KeyValue, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, system)...),
+	)
+}
+
+// Database system attributes used on repository spans
+var (
+	dbSystemPostgres = semconv.DBSystemPostgreSQL
+	dbSystemRedis    = semconv.DBSystemRedis
+)
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+	// Wednesday
+	from := time.Date(2024, 5, 15, 10, 7, 30, 0, istanbul)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 15, 10, 8, 0, 0, istanbul)},
+		{"*/15 * * * *", time.Date(2024, 5, 15, 10, 15, 0, 0, istanbul)},
+		{"0 9-17/4 * * *", time.Date(2024, 5, 15, 13, 0, 0, 0, istanbul)},
+		{"30 8 * * mon-fri", time.Date(2024, 5, 16, 8, 30, 0, 0, istanbul)},
+		{"0 10 * * sun", time.Date(2024, 5, 19, 10, 0, 0, 0, istanbul)},
+		{"0 10 * * 7", time.Date(2024, 5, 19, 10, 0, 0, 0, istanbul)},
+		{"0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, istanbul)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, istanbul)},
+		// Either day field matches when both are restricted
+		{"0 0 1 * fri", time.Date(2024, 5, 17, 0, 0, 0, 0, istanbul)},
+		{"5,10 10 * * *", time.Date(2024, 5, 15, 10, 10, 0, 0, istanbul)},
+		{"@hourly", time.Date(2024, 5, 15, 11, 0, 0, 0, istanbul)},
+		{"@daily", time.Date(2024, 5, 16, 0, 0, 0, 0, istanbul)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr, istanbul)
+			require.NoError(t, err)
+			next := cron.Next(from)
+			assert.True(t, tt.want.Equal(next), "want %s, got %s", tt.want, next)
+		})
+	}
+}
+
+func TestCronNextInLocation(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+
+	cron, err := ParseCron("0 9 * * *", istanbul)
+	require.NoError(t, err)
+
+	// 09:00 in Istanbul is 06:00 UTC
+	next := cron.Next(time.Date(2024, 5, 15, 5, 0, 0, 0, time.UTC))
+	assert.True(t, time.Date(2024, 5, 15, 6, 0, 0, 0, time.UTC).Equal(next))
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@sometimes",
+		// Valid fields, but the day never occurs
+		"0 0 30 2 *",
+		"0 0 31 apr,jun,sep,nov *",
+	} {
+		_, err := ParseCron(expr, nil)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,20 @@
+package schedule
+
+import "time"
+
+// Schedule decides when the next batch of messages is processed
+type Schedule interface {
+	// Next returns the first run time after t
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule running at a fixed interval
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
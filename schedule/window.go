@@ -0,0 +1,177 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // for images without a timezone database, like alpine
+)
+
+// Window is a daily time span in which messages may be sent, e.g. 09:00 to
+// 21:00 Europe/Istanbul from Monday to Saturday. A window ending at or before
+// its start runs past midnight into the next day.
+type Window struct {
+	days     [7]bool // indexed by time.Weekday, the day the window opens
+	start    int     // minutes after midnight
+	end      int
+	location *time.Location
+}
+
+// ParseWindow parses a window from start and end times as HH:MM, days of
+// the week as abbreviations or ranges of them (mon, mon-fri; all days when
+// empty) and an IANA timezone (UTC when empty)
+func ParseWindow(start, end string, days []string, timezone string) (Window, error) {
+	var w Window
+	var err error
+
+	if w.start, err = parseClock(start); err != nil {
+		return w, err
+	}
+	if w.end, err = parseClock(end); err != nil {
+		return w, err
+	}
+	if w.start == 24*60 {
+		return w, fmt.Errorf("invalid window start %q", start)
+	}
+
+	if len(days) == 0 {
+		for i := range w.days {
+			w.days[i] = true
+		}
+	}
+	for _, day := range days {
+		first, last, isRange := strings.Cut(strings.ToLower(strings.TrimSpace(day)), "-")
+		if !isRange {
+			last = first
+		}
+		from, to := dayIndex(first), dayIndex(last)
+		if from < 0 || to < 0 {
+			return w, fmt.Errorf("invalid window day %q", day)
+		}
+		// Ranges may wrap around the week, e.g. sat-mon
+		for d := from; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if w.location, err = time.LoadLocation(timezone); err != nil {
+		return w, fmt.Errorf("invalid window timezone %q: %w", timezone, err)
+	}
+	return w, nil
+}
+
+// parseClock parses HH:MM into minutes after midnight, allowing 24:00
+func parseClock(s string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil || len(s) != 5 ||
+		hour < 0 || minute < 0 || minute > 59 || hour*60+minute > 24*60 {
+		return 0, fmt.Errorf("invalid window time %q, use HH:MM", s)
+	}
+	return hour*60 + minute, nil
+}
+
+// dayIndex returns the time.Weekday of a day abbreviation, or -1
+func dayIndex(name string) int {
+	for i, day := range dayNames {
+		if name == day {
+			return i
+		}
+	}
+	return -1
+}
+
+// In returns the window with its times taken in another timezone
+func (w Window) In(location *time.Location) Window {
+	w.location = location
+	return w
+}
+
+// Location returns the timezone of the window
+func (w Window) Location() *time.Location {
+	return w.location
+}
+
+// opening returns when the window opens and closes on the day of t
+func (w Window) opening(t time.Time) (time.Time, time.Time) {
+	year, month, day := t.Date()
+	open := time.Date(year, month, day, w.start/60, w.start%60, 0, 0, w.location)
+	end := w.end
+	if end <= w.start {
+		end += 24 * 60
+	}
+	closes := time.Date(year, month, day, end/60, end%60, 0, 0, w.location)
+	return open, closes
+}
+
+// Contains reports whether t lies in the window
+func (w Window) Contains(t time.Time) bool {
+	t = t.In(w.location)
+	// A window opened the day before may still be open
+	for _, day := range []time.Time{t.AddDate(0, 0, -1), t} {
+		if !w.days[day.Weekday()] {
+			continue
+		}
+		open, closes := w.opening(day)
+		if !t.Before(open) && t.Before(closes) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns t when the window is open, otherwise when it opens next.
+// The zero time is returned for a window without days.
+func (w Window) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	local := t.In(w.location)
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		if !w.days[day.Weekday()] {
+			continue
+		}
+		if open, _ := w.opening(day); !open.Before(t) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+// Windows are the sending windows, messages may be sent when any of them is
+// open. No windows allow sending around the clock.
+type Windows []Window
+
+// Contains reports whether any window is open at t
+func (ws Windows) Contains(t time.Time) bool {
+	if len(ws) == 0 {
+		return true
+	}
+	for _, w := range ws {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hold returns t when a window is open, otherwise when the next one opens
+func (ws Windows) Hold(t time.Time) time.Time {
+	if ws.Contains(t) {
+		return t
+	}
+	var next time.Time
+	for _, w := range ws {
+		open := w.NextOpen(t)
+		if !open.IsZero() && (next.IsZero() || open.Before(next)) {
+			next = open
+		}
+	}
+	return next
+}
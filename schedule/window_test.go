@@ -0,0 +1,97 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowContains(t *testing.T) {
+	window, err := ParseWindow("09:00", "21:00", []string{"mon-sat"}, "Europe/Istanbul")
+	require.NoError(t, err)
+	istanbul := window.location
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2024, 5, 15, 9, 0, 0, 0, istanbul), true},
+		{time.Date(2024, 5, 15, 20, 59, 59, 0, istanbul), true},
+		{time.Date(2024, 5, 15, 21, 0, 0, 0, istanbul), false},
+		{time.Date(2024, 5, 15, 8, 59, 0, 0, istanbul), false},
+		// Sunday
+		{time.Date(2024, 5, 19, 12, 0, 0, 0, istanbul), false},
+		// 12:00 UTC is 15:00 in Istanbul
+		{time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 5, 15, 19, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, window.Contains(tt.at), tt.at.String())
+	}
+}
+
+func TestWindowPastMidnight(t *testing.T) {
+	window, err := ParseWindow("22:00", "02:00", []string{"fri"}, "")
+	require.NoError(t, err)
+
+	assert.True(t, window.Contains(time.Date(2024, 5, 17, 23, 0, 0, 0, time.UTC)))
+	// Still open on Saturday morning, since the window opened on Friday
+	assert.True(t, window.Contains(time.Date(2024, 5, 18, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, 5, 18, 2, 0, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, 5, 17, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestWindowNextOpen(t *testing.T) {
+	window, err := ParseWindow("09:00", "21:00", []string{"mon-sat"}, "Europe/Istanbul")
+	require.NoError(t, err)
+	istanbul := window.location
+
+	open := time.Date(2024, 5, 15, 10, 0, 0, 0, istanbul)
+	assert.Equal(t, open, window.NextOpen(open))
+
+	next := window.NextOpen(time.Date(2024, 5, 15, 7, 0, 0, 0, istanbul))
+	assert.True(t, time.Date(2024, 5, 15, 9, 0, 0, 0, istanbul).Equal(next))
+
+	// Saturday evening waits for Monday, skipping Sunday
+	next = window.NextOpen(time.Date(2024, 5, 18, 21, 30, 0, 0, istanbul))
+	assert.True(t, time.Date(2024, 5, 20, 9, 0, 0, 0, istanbul).Equal(next))
+}
+
+func TestWindowsHold(t *testing.T) {
+	morning, err := ParseWindow("09:00", "12:00", nil, "")
+	require.NoError(t, err)
+	evening, err := ParseWindow("18:00", "24:00", nil, "")
+	require.NoError(t, err)
+	windows := Windows{morning, evening}
+
+	at := func(hour int) time.Time { return time.Date(2024, 5, 15, hour, 0, 0, 0, time.UTC) }
+	assert.Equal(t, at(10), windows.Hold(at(10)))
+	assert.True(t, at(18).Equal(windows.Hold(at(13))))
+	assert.True(t, at(23).Equal(windows.Hold(at(23))))
+	assert.True(t, time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC).Equal(windows.Hold(at(24))))
+
+	// Without windows messages are sent around the clock
+	assert.Equal(t, at(3), Windows(nil).Hold(at(3)))
+	assert.True(t, Windows(nil).Contains(at(3)))
+}
+
+func TestParseWindowErrors(t *testing.T) {
+	for _, tt := range []struct {
+		start, end string
+		days       []string
+		timezone   string
+	}{
+		{"9:00", "21:00", nil, ""},
+		{"09:00", "25:00", nil, ""},
+		{"09:60", "21:00", nil, ""},
+		{"24:00", "21:00", nil, ""},
+		{"09:00", "21:00", []string{"someday"}, ""},
+		{"09:00", "21:00", []string{"mon-xyz"}, ""},
+		{"09:00", "21:00", nil, "Mars/Olympus"},
+	} {
+		_, err := ParseWindow(tt.start, tt.end, tt.days, tt.timezone)
+		assert.Error(t, err, "%+v", tt)
+	}
+}
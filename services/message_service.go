@@ -1,38 +1,147 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/alper.meric/messaging-system/clients"
+	"github.com/alper.meric/messaging-system/clock"
 	"github.com/alper.meric/messaging-system/config"
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/phone"
+	"github.com/alper.meric/messaging-system/recipient"
 	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/schedule"
+	"github.com/alper.meric/messaging-system/sms"
+	"github.com/alper.meric/messaging-system/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MessageServiceInterface defines the interface for the message service
 type MessageServiceInterface interface {
 	Start() error
-	Stop() error
+	Stop(ctx context.Context) error
+	Pause() error
+	Resume() error
+	Drain() error
+	RunOnce(ctx context.Context) models.RunSummary
+	Shutdown(ctx context.Context) error
 	Status() bool
-	GetSentMessages(page, limit int) ([]models.Message, int, error)
+	GetStatus(ctx context.Context) models.ServiceStatus
+	GetSentMessages(ctx context.Context, page, limit int) ([]models.Message, int, error)
+	ListMessages(ctx context.Context, filter repository.MessageFilter) (repository.MessagePage, error)
+	GetMessage(ctx context.Context, id int) (models.Message, error)
+	GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error)
+	CancelMessage(ctx context.Context, id int) (models.Message, error)
+	CreateMessage(ctx context.Context, msg models.Message) (models.Message, error)
+	PreviewContent(content, transliteration string) models.ContentPreview
+	UpdateMessage(ctx context.Context, id int, update repository.MessageUpdate) (models.Message, error)
+	DeleteMessage(ctx context.Context, id int) error
+	ResendMessage(ctx context.Context, id int) (models.Message, error)
+	GetResends(ctx context.Context, id int) ([]models.Message, error)
 }
 
+// ErrInvalidMessage is returned when a message change fails validation
+var ErrInvalidMessage = errors.New("invalid message")
+
+// ErrMessageNotResendable is returned when resending a message that has not
+// been sent or failed yet
+var ErrMessageNotResendable = errors.New("only sent or failed messages can be resent")
+
 // MessageService handles the message sending functionality
 type MessageService struct {
 	messageRepo   repository.MessageRepository
 	cacheRepo     repository.CacheRepository
 	messageClient *clients.MessageClient
-	running       bool
-	ticker        *time.Ticker
+	mode          string
 	stopChan      chan struct{}
+	wakeChan      chan struct{}
+	doneChan      chan struct{}
+	cancelRun     context.CancelFunc
 	batchSize     int
-	interval      time.Duration
-	maxLength     int
+	reserved      int // slots of each batch reserved for high-priority messages
+	phoneRegion   string
+	language      string // default transliteration, empty for none
+	schedule      schedule.Schedule
+	windows       schedule.Windows
+	quietHours    *recipient.QuietHours
+	clock         clock.Clock
+	maxLength     int // in characters
+	maxSegments   int // 0 for no limit
 	mutex         sync.Mutex
+	runMutex      sync.Mutex // serialises processing runs, scheduled or not
 	isInitialized bool
+	metrics       metrics.Metrics
+
+	// Runtime statistics, guarded by statsMutex
+	statsMutex    sync.Mutex
+	lastRunStart  time.Time
+	lastRunEnd    time.Time
+	nextRun       time.Time
+	lastRun       models.RunSummary
+	total         models.RunSummary
+	lastError     string
+	lastErrorTime time.Time
+}
+
+// Error classes recorded by the service in addition to the client's classes
+const (
+	errorClassContentTooLong = "content_too_long"
+	errorClassRepository     = "repository"
+)
+
+// updateAttempts is how often a content change is retried when the message
+// is changed concurrently
+const updateAttempts = 3
+
+// Option configures optional MessageService dependencies
+type Option func(*MessageService)
+
+// WithMetrics sets the metrics recorder used by the service
+func WithMetrics(m metrics.Metrics) Option {
+	return func(s *MessageService) {
+		s.metrics = m
+	}
+}
+
+// WithSchedule replaces the fixed interval between runs, e.g. with a cron schedule
+func WithSchedule(sched schedule.Schedule) Option {
+	return func(s *MessageService) {
+		s.schedule = sched
+	}
+}
+
+// WithSendingWindows restricts the scheduled runs to the given windows.
+// Runs falling outside them are held until the next window opens.
+func WithSendingWindows(windows schedule.Windows) Option {
+	return func(s *MessageService) {
+		s.windows = windows
+	}
+}
+
+// WithQuietHours holds promotional messages back during the recipient's quiet hours
+func WithQuietHours(q *recipient.QuietHours) Option {
+	return func(s *MessageService) {
+		s.quietHours = q
+	}
+}
+
+// WithClock sets the clock the scheduler waits on
+func WithClock(c clock.Clock) Option {
+	return func(s *MessageService) {
+		s.clock = c
+	}
 }
 
 // NewMessageService creates a new message service
@@ -41,17 +150,51 @@ func NewMessageService(
 	messageRepo repository.MessageRepository,
 	cacheRepo repository.CacheRepository,
 	messageClient *clients.MessageClient,
+	opts ...Option,
 ) MessageServiceInterface {
-	return &MessageService{
+	s := &MessageService{
 		messageRepo:   messageRepo,
 		cacheRepo:     cacheRepo,
 		messageClient: messageClient,
-		running:       false,
+		mode:          models.ServiceModeStopped,
 		batchSize:     cfg.App.MessageBatchSize,
-		interval:      time.Duration(cfg.App.MessageSendInterval) * time.Minute,
+		reserved:      reservedSlots(cfg.App.MessageBatchSize, cfg.App.HighPriorityShare),
+		phoneRegion:   cfg.App.DefaultPhoneRegion,
+		language:      strings.ToLower(cfg.App.Transliteration),
+		schedule:      schedule.Every(time.Duration(cfg.App.MessageSendInterval) * time.Minute),
+		clock:         clock.New(),
 		maxLength:     cfg.App.MaxContentLength,
+		maxSegments:   cfg.App.MaxSegments,
 		isInitialized: true,
+		metrics:       metrics.NewNoop(),
+	}
+
+	// Caching is optional, a missing cache behaves like a disabled one
+	if s.cacheRepo == nil {
+		s.cacheRepo = repository.NewNoopCacheRepository()
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// reservedSlots returns how many slots of a batch are reserved for
+// high-priority messages. A share of 0 reserves none and a share of 1 the
+// whole batch, so messages are claimed strictly by priority. Any share in
+// between reserves at least one slot and leaves at least one to lower
+// priorities; a batch of a single slot can't be shared and is reserved.
+func reservedSlots(batchSize int, share float64) int {
+	switch {
+	case share <= 0:
+		return 0
+	case share >= 1 || batchSize <= 1:
+		return batchSize
 	}
+	reserved := int(math.Ceil(float64(batchSize) * share))
+	return min(reserved, batchSize-1)
 }
 
 // Start begins the scheduled message sending
@@ -63,109 +206,966 @@ func (s *MessageService) Start() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if s.running {
+	if s.mode != models.ServiceModeStopped {
 		return errors.New("message service is already running")
 	}
 
-	log.Println("Starting message service...")
-	s.ticker = time.NewTicker(s.interval)
+	next := s.followingRun(s.clock.Now())
+	slog.Info("starting message service", "next_run", next, "batch_size", s.batchSize)
+	if next.IsZero() {
+		slog.Warn("the schedule has no further runs, messages are only sent on demand")
+	}
 	s.stopChan = make(chan struct{})
-	s.running = true
+	s.doneChan = make(chan struct{})
+	s.wakeChan = make(chan struct{}, 1)
+	s.mode = models.ServiceModeRunning
+	s.setNextRun(next)
+	s.metrics.SetServiceRunning(true)
+
+	// Cancelled by Shutdown when the batch in progress does not finish in time
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancelRun = cancel
+
+	go s.run(ctx, next, s.stopChan, s.wakeChan, s.doneChan)
+	return nil
+}
+
+// Stop stops the scheduled message sending and waits until the current batch
+// has finished or ctx is done. The scheduler is stopped either way, a batch
+// that outlasts ctx is finished in the background.
+func (s *MessageService) Stop(ctx context.Context) error {
+	done, _, err := s.stopScheduler()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("message service stopped, but the current batch has not finished: %w", ctx.Err())
+	}
+}
+
+// Shutdown stops the scheduler and waits until the batch in progress, if any,
+// has finished or ctx is done. When ctx is done first the batch is cancelled so
+// pending database and webhook calls are aborted. Unlike Stop it succeeds when
+// the service is not running, so it can always be called on process exit.
+func (s *MessageService) Shutdown(ctx context.Context) error {
+	// The scheduler may have been stopped through the API already while its
+	// batch is still running, which is waited for all the same
+	done, cancelRun, _ := s.stopScheduler()
+	if cancelRun == nil {
+		cancelRun = func() {}
+	}
+	defer cancelRun()
+
+	// Batches run on demand don't belong to the scheduler, they hold runMutex
+	for _, finished := range []<-chan struct{}{done, s.idle()} {
+		if finished == nil {
+			continue
+		}
+		select {
+		case <-finished:
+		case <-ctx.Done():
+			cancelRun()
+			return fmt.Errorf("message service did not finish the current batch: %w", ctx.Err())
+		}
+	}
 
-	go s.run()
+	slog.Info("message service shut down")
 	return nil
 }
 
-// Stop stops the scheduled message sending
-func (s *MessageService) Stop() error {
+// idle returns a channel that is closed once no batch is being processed
+func (s *MessageService) idle() <-chan struct{} {
+	idle := make(chan struct{})
+	go func() {
+		s.runMutex.Lock()
+		defer s.runMutex.Unlock()
+		close(idle)
+	}()
+	return idle
+}
+
+// stopScheduler signals the scheduler goroutine to exit and returns a channel
+// that is closed once it has done so, together with the function cancelling
+// the batch in progress. When the service is not running they belong to the
+// scheduler stopped last, or are nil if it never ran.
+func (s *MessageService) stopScheduler() (<-chan struct{}, context.CancelFunc, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.mode == models.ServiceModeStopped {
+		return s.doneChan, s.cancelRun, errors.New("message service is not running")
+	}
+
+	slog.Info("stopping message service")
+	close(s.stopChan)
+	s.mode = models.ServiceModeStopped
+	s.setNextRun(time.Time{})
+	s.metrics.SetServiceRunning(false)
+	return s.doneChan, s.cancelRun, nil
+}
+
+// Pause keeps the scheduler alive but skips its runs until Resume is called.
+// A batch in progress is finished.
+func (s *MessageService) Pause() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	if !s.running {
+	switch s.mode {
+	case models.ServiceModeStopped:
 		return errors.New("message service is not running")
+	case models.ServiceModePaused:
+		return errors.New("message service is already paused")
 	}
 
-	log.Println("Stopping message service...")
-	s.ticker.Stop()
-	s.stopChan <- struct{}{}
-	s.running = false
+	slog.Info("pausing message service")
+	s.mode = models.ServiceModePaused
 	return nil
 }
 
+// Resume continues the scheduled runs of a paused service
+func (s *MessageService) Resume() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.mode != models.ServiceModePaused {
+		return errors.New("message service is not paused")
+	}
+
+	slog.Info("resuming message service")
+	s.mode = models.ServiceModeRunning
+	return nil
+}
+
+// Drain runs batches back to back, ignoring the schedule, until the queue is
+// empty and then returns to the normal schedule, which starts over from the
+// end of the drain. Draining also ends early when a batch sends nothing, so a
+// failing webhook is not retried in a tight loop, or a sending window closes.
+func (s *MessageService) Drain() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch s.mode {
+	case models.ServiceModeStopped:
+		return errors.New("message service is not running")
+	case models.ServiceModeDraining:
+		return errors.New("message service is already draining")
+	}
+
+	slog.Info("draining message queue")
+	s.mode = models.ServiceModeDraining
+
+	// Wake the scheduler; a batch in progress is followed by the next one right away
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Mode returns the current mode of the service
+func (s *MessageService) Mode() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.mode
+}
+
+// finishDrain returns a draining service to the normal schedule
+func (s *MessageService) finishDrain() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.mode != models.ServiceModeDraining {
+		return
+	}
+
+	slog.Info("message queue drained, returning to the normal schedule")
+	s.mode = models.ServiceModeRunning
+}
+
+// RunOnce processes a batch of messages right away, without starting the
+// scheduler, and returns its summary. A run in progress is waited for, so
+// two batches never overlap. The batch is not bound to the deadline of ctx,
+// as a batch cut short would release its remaining messages.
+func (s *MessageService) RunOnce(ctx context.Context) models.RunSummary {
+	logging.FromContext(ctx).Info("running message processing on demand")
+	return s.processMessages(context.WithoutCancel(ctx))
+}
+
 // Status returns whether the service is running
 func (s *MessageService) Status() bool {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-	return s.running
+	return s.mode != models.ServiceModeStopped
+}
+
+// GetStatus returns the running state together with runtime statistics
+func (s *MessageService) GetStatus(ctx context.Context) models.ServiceStatus {
+	mode := s.Mode()
+	status := models.ServiceStatus{
+		IsRunning: mode != models.ServiceModeStopped,
+		Mode:      mode,
+	}
+
+	queueDepth, err := s.messageRepo.CountUnsentMessages(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to count unsent messages", logging.KeyError, err)
+	} else {
+		status.QueueDepth = &queueDepth
+		s.metrics.SetQueueDepth(queueDepth)
+	}
+
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+
+	status.LastRunTime = timePtr(s.lastRunStart)
+	status.LastRunEndTime = timePtr(s.lastRunEnd)
+	status.NextRunTime = timePtr(s.nextRun)
+	status.LastRun = s.lastRun
+	status.Total = s.total
+	status.LastError = s.lastError
+	status.LastErrorTime = timePtr(s.lastErrorTime)
+
+	return status
 }
 
 // GetSentMessages retrieves sent messages with pagination
-func (s *MessageService) GetSentMessages(page, limit int) ([]models.Message, int, error) {
-	return s.messageRepo.GetSentMessages(page, limit)
+func (s *MessageService) GetSentMessages(ctx context.Context, page, limit int) ([]models.Message, int, error) {
+	return s.messageRepo.GetSentMessages(ctx, page, limit)
+}
+
+// ListMessages retrieves a page of the messages matching a filter
+func (s *MessageService) ListMessages(ctx context.Context, filter repository.MessageFilter) (repository.MessagePage, error) {
+	return s.messageRepo.ListMessages(ctx, filter)
+}
+
+// GetMessage retrieves a message by its ID
+func (s *MessageService) GetMessage(ctx context.Context, id int) (models.Message, error) {
+	return s.messageRepo.GetMessage(ctx, id)
+}
+
+// GetMessageByExternalID retrieves a message by its external ID. The cache
+// entry written when the message was sent resolves it to the message ID, so
+// the database only has to be searched by external ID on a cache miss.
+func (s *MessageService) GetMessageByExternalID(ctx context.Context, externalID string) (models.Message, error) {
+	logger := logging.FromContext(ctx).With(logging.KeyExternalID, externalID)
+
+	cached, err := s.cacheRepo.GetCachedMessage(ctx, externalID)
+	switch {
+	case err == nil && cached.ID != 0:
+		msg, err := s.messageRepo.GetMessage(ctx, cached.ID)
+		if err == nil && msg.ExternalMsgID == externalID {
+			return msg, nil
+		}
+		if err != nil && !errors.Is(err, repository.ErrMessageNotFound) {
+			return models.Message{}, err
+		}
+		logger.Debug("stale message cache entry", logging.KeyMessageID, cached.ID)
+	case err != nil && !errors.Is(err, repository.ErrCacheMiss):
+		// The cache is only a shortcut, the database has the answer too
+		logger.Warn("failed to read message cache", logging.KeyError, err)
+	}
+
+	msg, err := s.messageRepo.GetMessageByExternalID(ctx, externalID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	if msg.IsSent {
+		if err := s.cacheRepo.CacheMessageID(ctx, externalID, msg.ID, msg.SentAt); err != nil {
+			logger.Warn("failed to cache message ID", logging.KeyError, err)
+		}
+	}
+
+	return msg, nil
+}
+
+// CancelMessage stops a queued message from being sent and returns it
+func (s *MessageService) CancelMessage(ctx context.Context, id int) (models.Message, error) {
+	if err := s.messageRepo.CancelMessage(ctx, id); err != nil {
+		return models.Message{}, err
+	}
+	return s.messageRepo.GetMessage(ctx, id)
+}
+
+// CreateMessage validates a new message, normalises its phone number to E.164
+// and queues it
+func (s *MessageService) CreateMessage(ctx context.Context, msg models.Message) (models.Message, error) {
+	if msg.Category == "" {
+		msg.Category = models.MessageCategoryTransactional
+	}
+	msg.Transliteration = strings.ToLower(msg.Transliteration)
+	if err := validateTransliteration(msg.Transliteration); err != nil {
+		return models.Message{}, err
+	}
+	info, err := s.validateContent(msg.Content, msg.Transliteration)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if err := validateCategory(msg.Category); err != nil {
+		return models.Message{}, err
+	}
+	if err := validatePriority(msg.Priority); err != nil {
+		return models.Message{}, err
+	}
+	number, err := s.parsePhoneNumber(msg.PhoneNumber)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	id, err := s.messageRepo.AddMessage(ctx, models.Message{
+		Content:         msg.Content,
+		PhoneNumber:     number.E164(),
+		CountryCode:     number.Region,
+		Encoding:        string(info.Encoding),
+		Segments:        info.Segments,
+		Category:        msg.Category,
+		Priority:        msg.Priority,
+		ScheduledAt:     msg.ScheduledAt,
+		Transliteration: msg.Transliteration,
+	})
+	if err != nil {
+		return models.Message{}, err
+	}
+	return s.messageRepo.GetMessage(ctx, id)
+}
+
+// UpdateMessage changes a queued message and returns it
+func (s *MessageService) UpdateMessage(ctx context.Context, id int, update repository.MessageUpdate) (models.Message, error) {
+	if err := s.validateUpdate(&update); err != nil {
+		return models.Message{}, err
+	}
+	if update.PhoneNumber != nil {
+		number, err := s.parsePhoneNumber(*update.PhoneNumber)
+		if err != nil {
+			return models.Message{}, err
+		}
+		phoneNumber := number.E164()
+		update.PhoneNumber, update.CountryCode = &phoneNumber, &number.Region
+	}
+	if update.Content == nil && update.Transliteration == nil {
+		if err := s.messageRepo.UpdateMessage(ctx, id, update); err != nil {
+			return models.Message{}, err
+		}
+		return s.messageRepo.GetMessage(ctx, id)
+	}
+
+	// How the content is sent depends on both, one of them may be unchanged.
+	// The update only applies to the content that was read, so a concurrent
+	// change to the other is read again instead of being overwritten.
+	for attempt := 1; ; attempt++ {
+		current, err := s.messageRepo.GetMessage(ctx, id)
+		if err != nil {
+			return models.Message{}, err
+		}
+		content, transliteration := current.Content, current.Transliteration
+		if update.Content != nil {
+			content = *update.Content
+		}
+		if update.Transliteration != nil {
+			transliteration = *update.Transliteration
+		}
+		info, err := s.validateContent(content, transliteration)
+		if err != nil {
+			return models.Message{}, err
+		}
+		encoding := string(info.Encoding)
+		update.Encoding, update.Segments = &encoding, &info.Segments
+		update.ExpectedContent, update.ExpectedTransliteration = &current.Content, &current.Transliteration
+
+		err = s.messageRepo.UpdateMessage(ctx, id, update)
+		if err == nil {
+			return s.messageRepo.GetMessage(ctx, id)
+		}
+		if !errors.Is(err, repository.ErrMessageChanged) || attempt == updateAttempts {
+			return models.Message{}, err
+		}
+	}
+}
+
+// DeleteMessage removes a queued or cancelled message
+func (s *MessageService) DeleteMessage(ctx context.Context, id int) error {
+	return s.messageRepo.DeleteMessage(ctx, id)
+}
+
+// ResendMessage queues a copy of a sent or failed message and, when the
+// scheduler would send it now, sends it right away instead of waiting for the
+// next run. While the service is stopped or paused, or outside the sending
+// windows, the copy is queued with high priority and goes out first once
+// sending resumes. The copy references the original, also when a resend is
+// resent, and is returned with the outcome of the send. A copy that could not
+// be delivered stays queued like any other message.
+func (s *MessageService) ResendMessage(ctx context.Context, id int) (models.Message, error) {
+	original, err := s.messageRepo.GetMessage(ctx, id)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if status := original.Status(); status != models.MessageStatusSent && status != models.MessageStatusFailed {
+		return models.Message{}, fmt.Errorf("%w: message %d is %s", ErrMessageNotResendable, id, status)
+	}
+
+	parentID := original.ID
+	if original.ParentID != nil {
+		parentID = *original.ParentID
+	}
+
+	// Sending under the run lock keeps resends from overlapping batches and
+	// lets Shutdown wait for them. Like a batch, the resend is not cut short
+	// by the deadline of the request once it got its turn.
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
+	ctx = context.WithoutCancel(ctx)
+
+	mode := s.Mode()
+	now := s.clock.Now()
+	sendNow := (mode == models.ServiceModeRunning || mode == models.ServiceModeDraining) && s.windows.Contains(now)
+
+	info := sms.Analyze(s.outgoingContent(original.Content, original.Transliteration))
+	resend := models.Message{
+		Content:         original.Content,
+		PhoneNumber:     original.PhoneNumber,
+		CountryCode:     original.CountryCode,
+		Encoding:        string(info.Encoding),
+		Segments:        info.Segments,
+		Category:        original.Category,
+		Priority:        original.Priority,
+		ParentID:        &parentID,
+		Transliteration: original.Transliteration,
+	}
+	if sendNow {
+		// The copy is created claimed, so it can't be changed while it is sent
+		resend.ClaimedAt = &now
+	} else {
+		resend.Priority = models.MessagePriorityHigh
+	}
+	// Messages added to the database directly may not be normalised yet; a
+	// number that can't be parsed was accepted before and is kept as it is
+	if number, err := phone.Parse(original.PhoneNumber, s.phoneRegion); err == nil {
+		resend.PhoneNumber, resend.CountryCode = number.E164(), number.Region
+	}
+	resend.ID, err = s.messageRepo.AddMessage(ctx, resend)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("parent_id", parentID))
+	if !sendNow {
+		logging.FromContext(ctx).Info("queued message resend until sending resumes", logging.KeyMessageID, resend.ID, "mode", mode)
+		s.updateQueueDepth(ctx)
+		return s.messageRepo.GetMessage(ctx, resend.ID)
+	}
+	logging.FromContext(ctx).Info("resending message", logging.KeyMessageID, resend.ID)
+
+	var summary models.RunSummary
+	s.sendMessage(ctx, s.messageClient.Provider(), resend).count(&summary)
+
+	s.statsMutex.Lock()
+	s.total.Add(summary)
+	s.statsMutex.Unlock()
+	s.updateQueueDepth(ctx)
+
+	return s.messageRepo.GetMessage(ctx, resend.ID)
+}
+
+// GetResends retrieves the manual resends of a message, oldest first
+func (s *MessageService) GetResends(ctx context.Context, id int) ([]models.Message, error) {
+	return s.messageRepo.GetResends(ctx, id)
+}
+
+// validateUpdate applies the rules messages are sent by to a change and
+// normalises the language of its transliteration
+func (s *MessageService) validateUpdate(update *repository.MessageUpdate) error {
+	if update.Content == nil && update.PhoneNumber == nil && update.Category == nil && update.Priority == nil && update.Transliteration == nil && update.ScheduledAt == nil && !update.ClearSchedule {
+		return fmt.Errorf("%w: nothing to update", ErrInvalidMessage)
+	}
+	// The segments depend on the transliteration of the message and are
+	// checked once it is known
+	if update.Content != nil {
+		if err := s.validateLength(*update.Content); err != nil {
+			return err
+		}
+	}
+	if update.Transliteration != nil {
+		transliteration := strings.ToLower(*update.Transliteration)
+		if err := validateTransliteration(transliteration); err != nil {
+			return err
+		}
+		update.Transliteration = &transliteration
+	}
+	if update.Category != nil {
+		if err := validateCategory(*update.Category); err != nil {
+			return err
+		}
+	}
+	if update.Priority != nil {
+		return validatePriority(*update.Priority)
+	}
+	return nil
+}
+
+// validateContent checks message content against the configured limits and
+// returns how it is sent as SMS
+func (s *MessageService) validateContent(content, transliteration string) (sms.Info, error) {
+	if err := s.validateLength(content); err != nil {
+		return sms.Info{}, err
+	}
+	info := sms.Analyze(s.outgoingContent(content, transliteration))
+	if limit := s.exceededLimit(content, info); limit != "" {
+		return info, fmt.Errorf("%w: content exceeds %s", ErrInvalidMessage, limit)
+	}
+	return info, nil
+}
+
+// validateLength checks that content is neither empty nor longer than the
+// configured number of characters
+func (s *MessageService) validateLength(content string) error {
+	if content == "" {
+		return fmt.Errorf("%w: content must not be empty", ErrInvalidMessage)
+	}
+	if utf8.RuneCountInString(content) > s.maxLength {
+		return fmt.Errorf("%w: content exceeds maximum length of %d characters", ErrInvalidMessage, s.maxLength)
+	}
+	return nil
+}
+
+// exceededLimit returns the content limit a message exceeds, empty when it
+// fits. The length applies to the content as stored, the segments to the
+// content as sent.
+func (s *MessageService) exceededLimit(content string, info sms.Info) string {
+	switch {
+	case utf8.RuneCountInString(content) > s.maxLength:
+		return fmt.Sprintf("maximum length of %d characters", s.maxLength)
+	case s.maxSegments > 0 && info.Segments > s.maxSegments:
+		return fmt.Sprintf("maximum of %d segments", s.maxSegments)
+	}
+	return ""
+}
+
+// outgoingContent returns the content of a message as it is sent: transliterated
+// in the message's or the default language when that makes it fit GSM-7
+func (s *MessageService) outgoingContent(content, transliteration string) string {
+	language := transliteration
+	if language == "" {
+		language = s.language
+	}
+	if language == "" || language == models.MessageTransliterationOff {
+		return content
+	}
+	transliterated, _ := sms.Transliterate(content, language)
+	return transliterated
+}
+
+// PreviewContent returns the encoding and segments content is sent in, and
+// whether it fits the configured limits. transliteration overrides the
+// default transliteration like a message's.
+func (s *MessageService) PreviewContent(content, transliteration string) models.ContentPreview {
+	transliteration = strings.ToLower(transliteration)
+	if err := validateTransliteration(transliteration); err != nil {
+		return models.ContentPreview{
+			MaxCharacters: s.maxLength,
+			MaxSegments:   s.maxSegments,
+			Error:         strings.TrimPrefix(err.Error(), ErrInvalidMessage.Error()+": "),
+		}
+	}
+
+	outgoing := s.outgoingContent(content, transliteration)
+	info := sms.Analyze(outgoing)
+	preview := models.ContentPreview{
+		Encoding:         string(info.Encoding),
+		Characters:       info.Characters,
+		Units:            info.Units,
+		Segments:         info.Segments,
+		UnitsPerSegment:  info.UnitsPerSegment,
+		Remaining:        info.Remaining,
+		NonGSMCharacters: info.NonGSM,
+		MaxCharacters:    s.maxLength,
+		MaxSegments:      s.maxSegments,
+	}
+	if outgoing != content {
+		preview.Transliterated = outgoing
+	}
+	if _, err := s.validateContent(content, transliteration); err != nil {
+		preview.Error = strings.TrimPrefix(err.Error(), ErrInvalidMessage.Error()+": ")
+	}
+	preview.Valid = preview.Error == ""
+	return preview
+}
+
+// validateCategory checks that a message category is known
+func validateCategory(category string) error {
+	if category != models.MessageCategoryTransactional && category != models.MessageCategoryPromotional {
+		return fmt.Errorf("%w: category must be %s or %s", ErrInvalidMessage, models.MessageCategoryTransactional, models.MessageCategoryPromotional)
+	}
+	return nil
 }
 
-func (s *MessageService) run() {
+// validateTransliteration checks that a message's transliteration is a
+// supported language, off, or empty for the default
+func validateTransliteration(transliteration string) error {
+	if transliteration != "" && transliteration != models.MessageTransliterationOff && !sms.SupportedLanguage(transliteration) {
+		return fmt.Errorf("%w: transliteration must be a supported language or %s", ErrInvalidMessage, models.MessageTransliterationOff)
+	}
+	return nil
+}
+
+// validatePriority checks that a message priority is in range
+func validatePriority(priority int) error {
+	if priority < models.MessagePriorityLow || priority > models.MessagePriorityHigh {
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidMessage, models.MessagePriorityLow, models.MessagePriorityHigh)
+	}
+	return nil
+}
+
+// parsePhoneNumber parses a recipient's phone number; numbers without a
+// country code are read in the default region
+func (s *MessageService) parsePhoneNumber(phoneNumber string) (phone.Number, error) {
+	number, err := phone.Parse(phoneNumber, s.phoneRegion)
+	if err != nil {
+		return phone.Number{}, fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+	}
+	return number, nil
+}
+
+func (s *MessageService) run(ctx context.Context, next time.Time, stop, wake <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
 	// Initial processing
-	s.processMessages()
+	s.scheduledRun(ctx, stop)
+
+	for {
+		// Without a next run only wake and stop requests are served, a timer
+		// for the zero time would fire immediately on every iteration
+		var timer clock.Timer = stoppedTimer{}
+		if !next.IsZero() {
+			timer = s.clock.NewTimer(next.Sub(s.clock.Now()))
+		}
+		select {
+		case <-timer.C():
+			// A stop request takes precedence over a timer that fired at the same time
+			select {
+			case <-stop:
+				slog.Info("message service stopped")
+				return
+			default:
+			}
+			next = s.followingRun(next)
+			s.setNextRun(next)
+			s.scheduledRun(ctx, stop)
+		case <-wake:
+			timer.Stop()
+			s.scheduledRun(ctx, stop)
+			select {
+			case <-stop:
+				slog.Info("message service stopped")
+				return
+			default:
+			}
+			next = s.followingRun(s.clock.Now())
+			s.setNextRun(next)
+		case <-stop:
+			timer.Stop()
+			slog.Info("message service stopped")
+			return
+		}
+	}
+}
+
+// stoppedTimer is a timer that never fires
+type stoppedTimer struct{}
+
+func (stoppedTimer) C() <-chan time.Time { return nil }
+func (stoppedTimer) Stop() bool          { return false }
+
+// followingRun returns the run after the one scheduled at t, or the zero time
+// when the schedule has no further runs. Runs missed
+// while a batch took longer than the schedule are skipped, and runs outside
+// the sending windows are held until the next window opens.
+func (s *MessageService) followingRun(t time.Time) time.Time {
+	next := s.schedule.Next(t)
+	if now := s.clock.Now(); next.Before(now) {
+		next = s.schedule.Next(now)
+	}
+	if next.IsZero() {
+		return next
+	}
+	return s.windows.Hold(next)
+}
 
+// scheduledRun processes a batch unless the service is paused or outside its
+// sending windows, and keeps processing batches while it is draining
+func (s *MessageService) scheduledRun(ctx context.Context, stop <-chan struct{}) {
 	for {
+		if s.Mode() == models.ServiceModePaused {
+			slog.Debug("message service paused, skipping run")
+			return
+		}
+		if !s.windows.Contains(s.clock.Now()) {
+			slog.Debug("outside the sending windows, holding messages")
+			s.finishDrain()
+			return
+		}
+
+		summary := s.processMessages(ctx)
+		if s.Mode() != models.ServiceModeDraining {
+			return
+		}
+
+		// A short batch means the queue is empty, a batch without a single sent
+		// message that the webhook is failing
+		processed := summary.Sent + summary.Failed + summary.Skipped + summary.Deferred
+		if processed < s.batchSize || summary.Sent == 0 || ctx.Err() != nil {
+			s.finishDrain()
+			return
+		}
+
 		select {
-		case <-s.ticker.C:
-			s.processMessages()
-		case <-s.stopChan:
-			log.Println("Message service stopped")
+		case <-stop:
 			return
+		default:
 		}
 	}
 }
 
-func (s *MessageService) processMessages() {
-	log.Println("Processing unsent messages...")
+func (s *MessageService) processMessages(ctx context.Context) models.RunSummary {
+	var summary models.RunSummary
+
+	s.runMutex.Lock()
+	defer s.runMutex.Unlock()
 
-	// Get unsent messages from the repository
-	messages, err := s.messageRepo.GetUnsentMessages(s.batchSize)
+	ctx, span := tracing.Start(ctx, "MessageService.processMessages",
+		trace.WithAttributes(attribute.Int("batch.size", s.batchSize)),
+	)
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("messages.sent", summary.Sent),
+			attribute.Int("messages.failed", summary.Failed),
+			attribute.Int("messages.skipped", summary.Skipped),
+			attribute.Int("messages.deferred", summary.Deferred),
+		)
+		span.End()
+	}()
+
+	s.statsMutex.Lock()
+	s.lastRunStart = s.clock.Now()
+	s.statsMutex.Unlock()
+
+	defer func() {
+		s.statsMutex.Lock()
+		s.lastRunEnd = s.clock.Now()
+		s.lastRun = summary
+		s.total.Add(summary)
+		s.statsMutex.Unlock()
+
+		s.updateQueueDepth(context.WithoutCancel(ctx))
+	}()
+
+	provider := s.messageClient.Provider()
+
+	logger := logging.FromContext(ctx)
+	logger.Debug("processing unsent messages", logging.KeyProvider, provider)
+
+	// Claim unsent messages so they can no longer be changed through the API
+	messages, err := s.messageRepo.ClaimUnsentMessages(ctx, s.batchSize, s.reserved)
 	if err != nil {
-		log.Printf("Error getting unsent messages: %v", err)
-		return
+		logger.Error("failed to get unsent messages", logging.KeyError, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		s.recordError(err)
+		return summary
 	}
 
 	if len(messages) == 0 {
-		log.Println("No unsent messages found")
-		return
+		logger.Debug("no unsent messages found")
+		return summary
 	}
 
-	log.Printf("Found %d unsent messages to process", len(messages))
+	logger.Info("processing unsent messages", logging.KeyProvider, provider, "count", len(messages))
 
 	// Process each message
-	for _, msg := range messages {
-		// Validate message content
-		if len(msg.Content) > s.maxLength {
-			log.Printf("Message %d content exceeds maximum length (%d > %d)", msg.ID, len(msg.Content), s.maxLength)
-			continue
+	for i, msg := range messages {
+		if ctx.Err() != nil {
+			logger.Warn("message processing interrupted", logging.KeyError, ctx.Err())
+			s.releaseMessages(ctx, messages[i:])
+			break
 		}
 
-		// Send the message using the HTTP client
-		externalID, err := s.messageClient.SendMessage(msg)
-		if err != nil {
-			log.Printf("Failed to send message %d: %v", msg.ID, err)
-			continue
+		result := s.sendMessage(ctx, provider, msg)
+		if result == resultInterrupted {
+			s.releaseMessages(ctx, messages[i:])
+			break
 		}
+		result.count(&summary)
+	}
 
-		// Mark as sent in repository
-		err = s.messageRepo.MarkMessageAsSent(msg.ID, externalID)
-		if err != nil {
-			log.Printf("Failed to mark message %d as sent: %v", msg.ID, err)
-			continue
+	return summary
+}
+
+// sendResult is the outcome of sending a single message
+type sendResult int
+
+const (
+	resultSent sendResult = iota
+	resultFailed
+	resultSkipped
+	resultDeferred
+	resultInterrupted // cancelled before the outcome was known, the message stays queued
+)
+
+// count adds the result to a run summary
+func (r sendResult) count(summary *models.RunSummary) {
+	switch r {
+	case resultSent:
+		summary.Sent++
+	case resultFailed:
+		summary.Failed++
+	case resultSkipped:
+		summary.Skipped++
+	case resultDeferred:
+		summary.Deferred++
+	}
+}
+
+// sendMessage sends a claimed message and records the outcome
+func (s *MessageService) sendMessage(ctx context.Context, provider string, msg models.Message) sendResult {
+	// The attempt number travels in the context so the client's log lines carry it too
+	attemptLogger := logging.FromContext(ctx).With(logging.KeyAttempt, msg.Attempts+1)
+	msgCtx := logging.WithLogger(ctx, attemptLogger)
+	msgLogger := attemptLogger.With(
+		logging.KeyMessageID, msg.ID,
+		logging.KeyProvider, provider,
+	)
+
+	// Promotional messages wait for the end of the recipient's quiet hours
+	if msg.Category == models.MessageCategoryPromotional && s.quietHours != nil {
+		now := s.clock.Now()
+		if until := s.quietHours.Next(msg.PhoneNumber, now); until.After(now) {
+			msgLogger.Info("deferring promotional message to the end of the recipient's quiet hours", "until", until)
+			if err := s.messageRepo.DeferMessage(context.WithoutCancel(msgCtx), msg.ID, until); err != nil {
+				// The claim expires eventually and the message is tried again
+				msgLogger.Error("failed to defer message", logging.KeyError, err)
+				s.recordError(err)
+			}
+			return resultDeferred
 		}
+	}
 
-		// Cache in Redis (bonus feature)
-		sentAt := time.Now()
-		err = s.cacheRepo.CacheMessageID(externalID, sentAt)
-		if err != nil {
-			log.Printf("Warning: Failed to cache message ID %s: %v", externalID, err)
-			// Continue anyway, as this is a non-critical operation
+	// Validate message content as it is sent; the stored content is kept as
+	// it was written
+	content := s.outgoingContent(msg.Content, msg.Transliteration)
+	info := sms.Analyze(content)
+	if limit := s.exceededLimit(msg.Content, info); limit != "" {
+		msgLogger.Warn("message content exceeds limit", "characters", info.Characters, "segments", info.Segments, "encoding", info.Encoding, "limit", limit)
+		s.metrics.MessageRejected(provider, errorClassContentTooLong)
+		// It can never be sent, so it leaves the queue as failed
+		s.recordSendFailure(msgCtx, msgLogger, msg.ID, "message content exceeds "+limit, true)
+		return resultSkipped
+	}
+
+	if content != msg.Content {
+		msgLogger.Debug("sending transliterated content", "encoding", info.Encoding, "segments", info.Segments)
+		msg.Content = content
+	}
+
+	// Send the message using the HTTP client
+	externalID, err := s.messageClient.SendMessage(msgCtx, msg)
+	if err != nil && ctx.Err() != nil {
+		// Not a delivery failure: the message stays unsent and is picked up by the next run
+		msgLogger.Warn("message sending interrupted", logging.KeyError, err)
+		return resultInterrupted
+	}
+	if err != nil {
+		msgLogger.Error("failed to send message", logging.KeyError, err, "error_class", clients.ErrorClass(err))
+		s.recordError(err)
+		if clients.IsRejection(err) {
+			s.metrics.MessageRejected(provider, clients.ErrorClass(err))
+		} else {
+			s.metrics.MessageFailed(provider, clients.ErrorClass(err))
 		}
+		// A rejected message would be rejected again, anything else is retried by the next run
+		s.recordSendFailure(context.WithoutCancel(msgCtx), msgLogger, msg.ID, err.Error(), clients.IsRejection(err))
+		return resultFailed
+	}
+
+	msgLogger = msgLogger.With(logging.KeyExternalID, externalID)
+
+	// Once the webhook accepted the message, recording it must not be cut off by
+	// cancellation, otherwise it would be sent again by the next run
+	msgCtx = context.WithoutCancel(msgCtx)
+
+	// Mark as sent in repository
+	err = s.messageRepo.MarkMessageAsSent(msgCtx, msg.ID, externalID)
+	if err != nil {
+		msgLogger.Error("failed to mark message as sent", logging.KeyError, err)
+		s.recordError(err)
+		s.metrics.MessageFailed(provider, errorClassRepository)
+		return resultFailed
+	}
+	s.metrics.MessageSent(provider)
+
+	// Cache in Redis (bonus feature)
+	sentAt := s.clock.Now()
+	err = s.cacheRepo.CacheMessageID(msgCtx, externalID, msg.ID, sentAt)
+	if err != nil {
+		msgLogger.Warn("failed to cache message ID", logging.KeyError, err)
+		// Continue anyway, as this is a non-critical operation
+	}
+
+	msgLogger.Info("message sent")
+	return resultSent
+}
+
+// updateQueueDepth refreshes the queue depth gauge after a run
+func (s *MessageService) updateQueueDepth(ctx context.Context) {
+	queueDepth, err := s.messageRepo.CountUnsentMessages(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to count unsent messages", logging.KeyError, err)
+		return
+	}
+	s.metrics.SetQueueDepth(queueDepth)
+}
+
+// releaseMessages gives up the claim on messages a run did not get to, so they
+// can be changed again before the next run picks them up
+func (s *MessageService) releaseMessages(ctx context.Context, messages []models.Message) {
+	ids := make([]int, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+	if err := s.messageRepo.ReleaseMessages(context.WithoutCancel(ctx), ids); err != nil {
+		logging.FromContext(ctx).Error("failed to release claimed messages", logging.KeyError, err)
+	}
+}
+
+// recordSendFailure stores a failed attempt on the message. Failing to do so
+// is only logged; the message stays in the queue either way.
+func (s *MessageService) recordSendFailure(ctx context.Context, logger *slog.Logger, id int, reason string, permanent bool) {
+	if err := s.messageRepo.RecordSendFailure(ctx, id, reason, permanent); err != nil {
+		logger.Error("failed to record send failure", logging.KeyError, err)
+	}
+}
+
+// setNextRun records when the scheduler will process messages next
+func (s *MessageService) setNextRun(next time.Time) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	s.nextRun = next
+}
+
+// recordError remembers the most recent processing error for status reports
+func (s *MessageService) recordError(err error) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+	s.lastError = err.Error()
+	s.lastErrorTime = s.clock.Now()
+}
 
-		log.Printf("Successfully sent message %d to %s (external ID: %s)", msg.ID, msg.PhoneNumber, externalID)
+// timePtr returns nil for the zero time so it is omitted from JSON
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
 	}
+	return &t
 }
@@ -1,16 +1,38 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/alper.meric/messaging-system/clients"
+	"github.com/alper.meric/messaging-system/clock"
 	"github.com/alper.meric/messaging-system/config"
+	"github.com/alper.meric/messaging-system/logging"
+	"github.com/alper.meric/messaging-system/metrics"
 	mocks "github.com/alper.meric/messaging-system/mocks/repository"
 	"github.com/alper.meric/messaging-system/models"
+	"github.com/alper.meric/messaging-system/recipient"
+	"github.com/alper.meric/messaging-system/repository"
+	"github.com/alper.meric/messaging-system/schedule"
+	"github.com/alper.meric/messaging-system/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // MessageServiceTestSuite, MessageService için test suite
@@ -58,6 +80,7 @@ func (suite *MessageServiceTestSuite) SetupTest() {
 	suite.config = &config.Configuration{
 		App: config.AppConfig{
 			MessageBatchSize:    2,
+			HighPriorityShare:   1, // mesajlar kesin öncelik sırasıyla alınır
 			WebhookURL:          "https://test.example.com",
 			MaxContentLength:    1000,
 			MessageSendDryRun:   true,
@@ -83,8 +106,9 @@ func (suite *MessageServiceTestSuite) TestNewMessageService() {
 
 // TestStartStop, servis başlatma ve durdurma testleri
 func (suite *MessageServiceTestSuite) TestStartStop() {
-	// GetUnsentMessages mock ayarı (processMessages için)
-	suite.mockMsgRepo.EXPECT().GetUnsentMessages(suite.config.App.MessageBatchSize).Return([]models.Message{}, nil).Maybe()
+	// ClaimUnsentMessages mock ayarı (processMessages için)
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return([]models.Message{}, nil).Maybe()
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil).Maybe()
 
 	// Başlat
 	err := suite.messageService.Start()
@@ -96,19 +120,20 @@ func (suite *MessageServiceTestSuite) TestStartStop() {
 	assert.Error(suite.T(), err, "Zaten çalışan servisi başlatmaya çalışırken hata olmalı")
 
 	// Durdur
-	err = suite.messageService.Stop()
+	err = suite.messageService.Stop(context.Background())
 	assert.NoError(suite.T(), err, "Stop fonksiyonu hata döndürmemeli")
 	assert.False(suite.T(), suite.messageService.Status(), "Stop fonksiyonu çağrıldıktan sonra Status() false olmalı")
 
 	// Zaten durdurulmuşken tekrar durdurma
-	err = suite.messageService.Stop()
+	err = suite.messageService.Stop(context.Background())
 	assert.Error(suite.T(), err, "Zaten durmuş servisi durdurmaya çalışırken hata olmalı")
 }
 
 // TestStatus, servis durumu testleri
 func (suite *MessageServiceTestSuite) TestStatus() {
-	// GetUnsentMessages mock ayarı (processMessages için)
-	suite.mockMsgRepo.EXPECT().GetUnsentMessages(suite.config.App.MessageBatchSize).Return([]models.Message{}, nil).Maybe()
+	// ClaimUnsentMessages mock ayarı (processMessages için)
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return([]models.Message{}, nil).Maybe()
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil).Maybe()
 
 	// Başlangıç durumu
 	assert.False(suite.T(), suite.messageService.Status(), "Başlangıçta servis durumu false olmalı")
@@ -121,10 +146,10 @@ func (suite *MessageServiceTestSuite) TestStatus() {
 // TestGetSentMessages, gönderilmiş mesajları getirme testi
 func (suite *MessageServiceTestSuite) TestGetSentMessages() {
 	// Mock davranışını ayarla
-	suite.mockMsgRepo.EXPECT().GetSentMessages(1, 10).Return(suite.testMessages, 1, nil)
+	suite.mockMsgRepo.EXPECT().GetSentMessages(mock.Anything, 1, 10).Return(suite.testMessages, 1, nil)
 
 	// GetSentMessages fonksiyonunu test et
-	messages, total, err := suite.messageService.GetSentMessages(1, 10)
+	messages, total, err := suite.messageService.GetSentMessages(context.Background(), 1, 10)
 
 	assert.NoError(suite.T(), err, "GetSentMessages fonksiyonu hata döndürmemeli")
 	assert.Equal(suite.T(), 1, total, "Toplam mesaj sayısı 1 olmalı")
@@ -132,15 +157,53 @@ func (suite *MessageServiceTestSuite) TestGetSentMessages() {
 	assert.Equal(suite.T(), suite.testMessages[0].ID, messages[0].ID, "Mesaj ID'leri eşleşmeli")
 }
 
+// TestGetMessageByExternalID, harici ID ile aramanın önce önbelleğe baktığını test eder
+func (suite *MessageServiceTestSuite) TestGetMessageByExternalID() {
+	ctx := context.Background()
+	sentAt := time.Now()
+	message := models.Message{ID: 7, IsSent: true, SentAt: sentAt, ExternalMsgID: "ext-7"}
+
+	// Önbellekte bulunursa mesaj birincil anahtarla okunur
+	suite.mockCacheRepo.EXPECT().GetCachedMessage(mock.Anything, "ext-7").Return(repository.CachedMessage{ID: 7, SentAt: sentAt}, nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 7).Return(message, nil).Once()
+
+	got, err := suite.messageService.GetMessageByExternalID(ctx, "ext-7")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), message, got)
+	suite.mockMsgRepo.AssertNotCalled(suite.T(), "GetMessageByExternalID", mock.Anything, mock.Anything)
+
+	// Önbellekte yoksa veritabanında aranır ve önbelleğe yazılır
+	suite.mockCacheRepo.EXPECT().GetCachedMessage(mock.Anything, "ext-7").Return(repository.CachedMessage{}, repository.ErrCacheMiss).Once()
+	suite.mockMsgRepo.EXPECT().GetMessageByExternalID(mock.Anything, "ext-7").Return(message, nil).Once()
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "ext-7", 7, sentAt).Return(nil).Once()
+
+	got, err = suite.messageService.GetMessageByExternalID(ctx, "ext-7")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), message, got)
+
+	// Eski önbellek kaydı ve önbellek hatası veritabanına düşer
+	suite.mockCacheRepo.EXPECT().GetCachedMessage(mock.Anything, "ext-8").Return(repository.CachedMessage{ID: 7, SentAt: sentAt}, nil).Once()
+	suite.mockCacheRepo.EXPECT().GetCachedMessage(mock.Anything, "ext-9").Return(repository.CachedMessage{}, errors.New("redis is down")).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 7).Return(message, nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessageByExternalID(mock.Anything, "ext-8").Return(models.Message{}, repository.ErrMessageNotFound).Once()
+	suite.mockMsgRepo.EXPECT().GetMessageByExternalID(mock.Anything, "ext-9").Return(models.Message{}, repository.ErrMessageNotFound).Once()
+
+	_, err = suite.messageService.GetMessageByExternalID(ctx, "ext-8")
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageNotFound)
+	_, err = suite.messageService.GetMessageByExternalID(ctx, "ext-9")
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageNotFound)
+}
+
 // TestProcessMessages, mesaj işleme testi
 func (suite *MessageServiceTestSuite) TestProcessMessages() {
 	// Mock davranışlarını ayarla
-	suite.mockMsgRepo.EXPECT().GetUnsentMessages(suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil)
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil)
 
 	// Beklenen external ID (dry run modunda)
 	expectedMsgID := "dry-run-id-2"
-	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(2, expectedMsgID).Return(nil)
-	suite.mockCacheRepo.EXPECT().CacheMessageID(expectedMsgID, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, expectedMsgID).Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, expectedMsgID, 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
 
 	// Servis tipine dönüştür
 	concreteService, ok := suite.messageService.(*MessageService)
@@ -150,11 +213,1071 @@ func (suite *MessageServiceTestSuite) TestProcessMessages() {
 	// Not: Gerçek HTTP isteği yapılmayacak, messageClient dry run modunda
 
 	// ProcessMessages metodunu doğrudan çağır
-	concreteService.processMessages()
+	concreteService.processMessages(context.Background())
 
 	// Beklenen mock çağrılarının gerçekleştiğini kontrol et (mock kütüphanesi tarafından otomatik olarak yapılır)
 }
 
+// TestGetStatus, işlem sonrası durum istatistiklerini test eder
+func (suite *MessageServiceTestSuite) TestGetStatus() {
+	longMessage := models.Message{ID: 3, PhoneNumber: "+90123456789", Content: strings.Repeat("a", suite.config.App.MaxContentLength+1)}
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(append(suite.unsentMessages, longMessage), nil)
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "dry-run-id-2").Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "dry-run-id-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().RecordSendFailure(mock.Anything, 3, "message content exceeds maximum length of 1000 characters", true).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(1, nil)
+
+	concreteService := suite.messageService.(*MessageService)
+	summary := concreteService.processMessages(context.Background())
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1, Skipped: 1}, summary)
+
+	status := suite.messageService.GetStatus(context.Background())
+
+	assert.False(suite.T(), status.IsRunning)
+	assert.NotNil(suite.T(), status.LastRunTime, "Son çalışma zamanı doldurulmalı")
+	assert.NotNil(suite.T(), status.LastRunEndTime, "Son çalışma bitiş zamanı doldurulmalı")
+	assert.Nil(suite.T(), status.NextRunTime, "Servis durmuşken sonraki çalışma zamanı olmamalı")
+	assert.Equal(suite.T(), summary, status.LastRun)
+	assert.Equal(suite.T(), summary, status.Total)
+	assert.Equal(suite.T(), 1, *status.QueueDepth)
+	assert.Empty(suite.T(), status.LastError)
+}
+
+// TestGetStatusRecordsErrors, işlem hatalarının duruma yansıdığını test eder
+func (suite *MessageServiceTestSuite) TestGetStatusRecordsErrors() {
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(nil, errors.New("database is down"))
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, errors.New("database is down"))
+
+	concreteService := suite.messageService.(*MessageService)
+	concreteService.processMessages(context.Background())
+
+	status := suite.messageService.GetStatus(context.Background())
+
+	assert.Equal(suite.T(), "database is down", status.LastError)
+	assert.NotNil(suite.T(), status.LastErrorTime)
+	assert.Nil(suite.T(), status.QueueDepth, "Kuyruk derinliği okunamazsa boş bırakılmalı")
+}
+
+// TestProcessMessagesMetrics, gönderim sonuçlarının metriklere yansıdığını test eder
+func (suite *MessageServiceTestSuite) TestProcessMessagesMetrics() {
+	// İlk mesaj 400 ile reddedilir, ikincisi 503 ile başarısız olur
+	responses := []int{http.StatusBadRequest, http.StatusServiceUnavailable}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(responses[0])
+		responses = responses[1:]
+	}))
+	defer server.Close()
+
+	recorder := metrics.NewMemoryMetrics()
+	client := clients.NewMessageClient(server.URL, false, clients.WithMetrics(recorder))
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, client, WithMetrics(recorder)).(*MessageService)
+
+	messages := []models.Message{
+		{ID: 4, PhoneNumber: "+90123456789", Content: "rejected"},
+		{ID: 5, PhoneNumber: "+90123456789", Content: "failed"},
+		{ID: 6, PhoneNumber: "+90123456789", Content: strings.Repeat("a", suite.config.App.MaxContentLength+1)},
+	}
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(messages, nil).Once()
+	// Reddedilen ve çok uzun mesajlar kalıcı, 503 ise geçici hata olarak kaydedilir
+	suite.mockMsgRepo.EXPECT().RecordSendFailure(mock.Anything, 4, mock.AnythingOfType("string"), true).Return(nil)
+	suite.mockMsgRepo.EXPECT().RecordSendFailure(mock.Anything, 5, mock.AnythingOfType("string"), false).Return(nil)
+	suite.mockMsgRepo.EXPECT().RecordSendFailure(mock.Anything, 6, mock.AnythingOfType("string"), true).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(3, nil)
+
+	summary := service.processMessages(context.Background())
+
+	assert.Equal(suite.T(), models.RunSummary{Failed: 2, Skipped: 1}, summary)
+	assert.Equal(suite.T(), 1, recorder.Count(metrics.MessagesRejected, clients.ProviderWebhook, clients.ErrorClassClientStatus))
+	assert.Equal(suite.T(), 1, recorder.Count(metrics.MessagesFailed, clients.ProviderWebhook, clients.ErrorClassServerStatus))
+	assert.Equal(suite.T(), 1, recorder.Count(metrics.MessagesRejected, clients.ProviderWebhook, errorClassContentTooLong))
+	assert.Equal(suite.T(), 2, recorder.Count(metrics.SendLatency, clients.ProviderWebhook))
+	assert.Equal(suite.T(), 3.0, recorder.Gauge(metrics.QueueDepth))
+
+	// Servis durumu metrikleri
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return([]models.Message{}, nil).Maybe()
+	assert.NoError(suite.T(), service.Start())
+	assert.Equal(suite.T(), 1.0, recorder.Gauge(metrics.ServiceRunning))
+	assert.NoError(suite.T(), service.Stop(context.Background()))
+	assert.Equal(suite.T(), 0.0, recorder.Gauge(metrics.ServiceRunning))
+}
+
+// TestProcessMessagesLogFields, mesaj log satırlarının korelasyon alanlarını test eder
+func (suite *MessageServiceTestSuite) TestProcessMessagesLogFields() {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(previous)
+
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil)
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "dry-run-id-2").Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "dry-run-id-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
+
+	suite.messageService.(*MessageService).processMessages(context.Background())
+
+	var sentLine map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		assert.NoError(suite.T(), json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "message sent" {
+			sentLine = entry
+		}
+	}
+
+	assert.NotNil(suite.T(), sentLine, "Başarılı gönderim loglanmalı")
+	assert.Equal(suite.T(), 2.0, sentLine[logging.KeyMessageID])
+	assert.Equal(suite.T(), "dry-run-id-2", sentLine[logging.KeyExternalID])
+	assert.Equal(suite.T(), clients.ProviderWebhook, sentLine[logging.KeyProvider])
+	assert.Equal(suite.T(), 1.0, sentLine[logging.KeyAttempt])
+}
+
+// TestProcessMessagesTracing, işlem ve alt çağrıların span'lerini test eder
+func (suite *MessageServiceTestSuite) TestProcessMessagesTracing() {
+	provider, exporter := tracing.NewInMemoryProvider()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil)
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "dry-run-id-2").Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "dry-run-id-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
+
+	suite.messageService.(*MessageService).processMessages(context.Background())
+
+	spans := exporter.GetSpans()
+	assert.Len(suite.T(), spans, 2)
+
+	var runSpan, sendSpan tracetest.SpanStub
+	for _, span := range spans {
+		switch span.Name {
+		case "MessageService.processMessages":
+			runSpan = span
+		case "MessageClient.SendMessage":
+			sendSpan = span
+		}
+	}
+
+	assert.True(suite.T(), runSpan.SpanContext.IsValid(), "İşlem span'i oluşturulmalı")
+	assert.Equal(suite.T(), runSpan.SpanContext.SpanID(), sendSpan.Parent.SpanID(), "Gönderim span'i işlem span'inin altında olmalı")
+	assert.Contains(suite.T(), runSpan.Attributes, attribute.Int("messages.sent", 1))
+}
+
+// TestShutdownWaitsForInFlightSend, kapanışın devam eden gönderimin bitmesini beklediğini test eder
+func (suite *MessageServiceTestSuite) TestShutdownWaitsForInFlightSend() {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"ext-2"}`))
+	}))
+	defer server.Close()
+
+	marked := make(chan struct{})
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil).Once()
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "ext-2").Run(func(context.Context, int, string) {
+		close(marked)
+	}).Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "ext-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
+
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, clients.NewMessageClient(server.URL, false))
+	assert.NoError(suite.T(), service.Start())
+	<-received
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- service.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdownErr:
+		suite.T().Fatal("Shutdown gönderim bitmeden dönmemeli")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.False(suite.T(), service.Status(), "Shutdown zamanlayıcıyı hemen durdurmalı")
+
+	close(release)
+	assert.NoError(suite.T(), <-shutdownErr)
+
+	select {
+	case <-marked:
+	default:
+		suite.T().Fatal("Mesaj Shutdown dönmeden önce gönderildi olarak işaretlenmeli")
+	}
+}
+
+// TestShutdownDeadline, süre dolduğunda Shutdown'ın devam eden gönderimi iptal ettiğini test eder
+func (suite *MessageServiceTestSuite) TestShutdownDeadline() {
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Bağlantının kapandığını fark etmek için gövde okunmalı
+		_, _ = io.Copy(io.Discard, r.Body)
+		close(received)
+		// İstemci isteği iptal edene kadar yanıt verme
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil).Once()
+	// Kesilen mesajın sahipliği bırakılmalı, böylece API üzerinden tekrar değiştirilebilir
+	released := make(chan struct{})
+	suite.mockMsgRepo.EXPECT().ReleaseMessages(mock.Anything, []int{2}).Run(func(context.Context, []int) {
+		close(released)
+	}).Return(nil).Once()
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(1, nil)
+
+	recorder := metrics.NewMemoryMetrics()
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo,
+		clients.NewMessageClient(server.URL, false), WithMetrics(recorder))
+	assert.NoError(suite.T(), service.Start())
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := service.Shutdown(ctx)
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+
+	// Gönderim iptal edildiği için işlem webhook zaman aşımını beklemeden bitmeli
+	select {
+	case <-service.(*MessageService).doneChan:
+	case <-time.After(time.Second):
+		suite.T().Fatal("Shutdown süresi dolduğunda devam eden gönderim iptal edilmeli")
+	}
+	<-released
+
+	// Kesilen gönderim hata olarak sayılmamalı, mesaj bir sonraki çalışmada tekrar denenir
+	status := service.GetStatus(context.Background())
+	assert.Equal(suite.T(), 0, status.LastRun.Failed)
+	assert.Empty(suite.T(), status.LastError)
+	assert.Equal(suite.T(), 0, recorder.Count(metrics.MessagesFailed, clients.ProviderWebhook, clients.ErrorClassCanceled))
+
+	// Çalışmayan servisi kapatmak hata döndürmemeli
+	assert.NoError(suite.T(), service.Shutdown(context.Background()))
+}
+
+// TestStopDeadline, Stop'un süre dolduğunda hata döndürdüğünü ve Shutdown'ın
+// durdurulmuş servisin devam eden gönderimini yine de beklediğini test eder
+func (suite *MessageServiceTestSuite) TestStopDeadline() {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"ext-2"}`))
+	}))
+	defer server.Close()
+
+	marked := make(chan struct{})
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil).Once()
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "ext-2").Run(func(context.Context, int, string) {
+		close(marked)
+	}).Return(nil)
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "ext-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
+
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, clients.NewMessageClient(server.URL, false))
+	assert.NoError(suite.T(), service.Start())
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := service.Stop(ctx)
+	assert.ErrorIs(suite.T(), err, context.DeadlineExceeded)
+	assert.False(suite.T(), service.Status(), "Süre dolsa da zamanlayıcı durdurulmalı")
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- service.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdownErr:
+		suite.T().Fatal("Shutdown durdurulmuş servisin gönderimi bitmeden dönmemeli")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.NoError(suite.T(), <-shutdownErr)
+	select {
+	case <-marked:
+	default:
+		suite.T().Fatal("Mesaj Shutdown dönmeden önce gönderildi olarak işaretlenmeli")
+	}
+}
+
+// TestProcessMessagesWithMemoryRepository, mock yerine bellek içi depolarla uçtan uca gönderimi test eder
+func (suite *MessageServiceTestSuite) TestProcessMessagesWithMemoryRepository() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	cacheRepo := repository.NewLRUCacheRepository(10)
+
+	var ids []int
+	for _, content := range []string{"first", "second", "third"} {
+		id, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: content})
+		assert.NoError(suite.T(), err)
+		ids = append(ids, id)
+		time.Sleep(time.Millisecond)
+	}
+
+	service := NewMessageService(suite.config, messageRepo, cacheRepo, suite.messageClient).(*MessageService)
+	summary := service.processMessages(ctx)
+
+	// Batch boyutu 2 olduğu için en eski iki mesaj gönderilmeli
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2}, summary)
+
+	unsent, err := messageRepo.ClaimUnsentMessages(ctx, 10, 0)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), unsent, 1)
+	assert.Equal(suite.T(), ids[2], unsent[0].ID)
+
+	_, total, err := messageRepo.GetSentMessages(ctx, 1, 10)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, total)
+
+	for _, id := range ids[:2] {
+		cached, err := cacheRepo.GetCachedMessage(ctx, fmt.Sprintf("dry-run-id-%d", id))
+		assert.NoError(suite.T(), err, "Gönderilen mesajlar önbelleğe alınmalı")
+		assert.Equal(suite.T(), id, cached.ID)
+	}
+
+	status := service.GetStatus(ctx)
+	assert.Equal(suite.T(), 1, *status.QueueDepth)
+}
+
+// TestUpdateMessage, mesaj değişikliklerinin doğrulanıp kaydedildiğini test eder
+func (suite *MessageServiceTestSuite) TestUpdateMessage() {
+	ctx := context.Background()
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, suite.messageClient)
+
+	// İçerikle birlikte kodlaması ve segment sayısı da güncellenir; bunlar
+	// mesajın harf çevirisine bağlı olduğundan önce mesaj okunur
+	content, encoding, segments := "changed", "GSM-7", 1
+	original, noTransliteration := "original", ""
+	update := repository.MessageUpdate{Content: &content}
+	stored := repository.MessageUpdate{Content: &content, Encoding: &encoding, Segments: &segments,
+		ExpectedContent: &original, ExpectedTransliteration: &noTransliteration}
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 1).Return(models.Message{ID: 1, Content: "original"}, nil).Once()
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 1, stored).Return(nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 1).Return(models.Message{ID: 1, Content: content}, nil).Once()
+
+	msg, err := service.UpdateMessage(ctx, 1, update)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), content, msg.Content)
+
+	// Gönderilemeyecek değişiklikler depoya ulaşmamalı
+	empty, long, longPhone := "", strings.Repeat("a", suite.config.App.MaxContentLength+1), "+9012345678901234567890"
+	unknownCategory, urgent, klingon := "marketing", 5, "tlh"
+	for _, invalid := range []repository.MessageUpdate{
+		{},
+		{Content: &empty},
+		{Content: &long},
+		{PhoneNumber: &empty},
+		{PhoneNumber: &longPhone},
+		{Category: &unknownCategory},
+		{Priority: &urgent},
+		{Transliteration: &klingon},
+	} {
+		_, err := service.UpdateMessage(ctx, 1, invalid)
+		assert.ErrorIs(suite.T(), err, ErrInvalidMessage)
+	}
+
+	// Yeni numara E.164'e çevrilir ve ülkesi kaydedilir
+	phoneNumber, e164, country := "+90 532 123 45 67", "+905321234567", "TR"
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 1, repository.MessageUpdate{PhoneNumber: &e164, CountryCode: &country}).Return(nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 1).Return(models.Message{ID: 1, PhoneNumber: e164, CountryCode: country}, nil).Once()
+	msg, err = service.UpdateMessage(ctx, 1, repository.MessageUpdate{PhoneNumber: &phoneNumber})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), e164, msg.PhoneNumber)
+
+	// Okunduktan sonra harf çevirisi değişen mesaj yeniden okunur ve kodlama
+	// yeni harf çevirisiyle hesaplanır
+	turkish, ucs2, tr := "şekerli", "UCS-2", "tr"
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 3).Return(models.Message{ID: 3, Content: "original"}, nil).Once()
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 3, repository.MessageUpdate{Content: &turkish, Encoding: &ucs2, Segments: &segments,
+		ExpectedContent: &original, ExpectedTransliteration: &noTransliteration}).Return(repository.ErrMessageChanged).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 3).Return(models.Message{ID: 3, Content: "original", Transliteration: tr}, nil).Once()
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 3, repository.MessageUpdate{Content: &turkish, Encoding: &encoding, Segments: &segments,
+		ExpectedContent: &original, ExpectedTransliteration: &tr}).Return(nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 3).Return(models.Message{ID: 3, Content: turkish, Transliteration: tr}, nil).Once()
+	_, err = service.UpdateMessage(ctx, 3, repository.MessageUpdate{Content: &turkish})
+	assert.NoError(suite.T(), err)
+
+	// Sürekli değişen mesajda deneme sayısı sınırlıdır
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 4).Return(models.Message{ID: 4, Content: "original"}, nil).Times(updateAttempts)
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 4, stored).Return(repository.ErrMessageChanged).Times(updateAttempts)
+	_, err = service.UpdateMessage(ctx, 4, update)
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageChanged)
+
+	// Gönderim için alınmış mesaj değiştirilemez
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 2).Return(models.Message{ID: 2, Content: "original"}, nil).Once()
+	suite.mockMsgRepo.EXPECT().UpdateMessage(mock.Anything, 2, stored).Return(repository.ErrMessageNotEditable).Once()
+	_, err = service.UpdateMessage(ctx, 2, update)
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageNotEditable)
+}
+
+// TestCreateMessage, yeni mesajların doğrulanıp numarasının E.164'e çevrildiğini test eder
+func (suite *MessageServiceTestSuite) TestCreateMessage() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	cfg := *suite.config
+	cfg.App.DefaultPhoneRegion = "TR"
+	service := NewMessageService(&cfg, messageRepo, nil, suite.messageClient)
+
+	// Aynı numaranın farklı yazımları aynı biçimde saklanır
+	for _, phoneNumber := range []string{"0532 123 45 67", "+90 532 123 45 67", "90 532 123 4567"} {
+		msg, err := service.CreateMessage(ctx, models.Message{PhoneNumber: phoneNumber, Content: "hello"})
+		if assert.NoError(suite.T(), err, phoneNumber) {
+			assert.Equal(suite.T(), "+905321234567", msg.PhoneNumber)
+			assert.Equal(suite.T(), "TR", msg.CountryCode)
+			assert.Equal(suite.T(), models.MessageCategoryTransactional, msg.Category)
+			assert.Equal(suite.T(), models.MessageStatusUnsent, msg.Status())
+		}
+	}
+
+	msg, err := service.CreateMessage(ctx, models.Message{PhoneNumber: "+44 20 7946 0958", Content: "hello", Priority: models.MessagePriorityHigh})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "GB", msg.CountryCode)
+	assert.Equal(suite.T(), models.MessagePriorityHigh, msg.Priority)
+
+	// Geçersiz mesajlar kaydedilmez
+	for _, invalid := range []models.Message{
+		{PhoneNumber: "0532 123 45", Content: "hello"},
+		{PhoneNumber: "+999 123 456 789", Content: "hello"},
+		{PhoneNumber: "", Content: "hello"},
+		{PhoneNumber: "05321234567", Content: ""},
+		{PhoneNumber: "05321234567", Content: "hello", Category: "marketing"},
+		{PhoneNumber: "05321234567", Content: "hello", Priority: 3},
+	} {
+		_, err := service.CreateMessage(ctx, invalid)
+		assert.ErrorIs(suite.T(), err, ErrInvalidMessage, invalid)
+	}
+	count, err := messageRepo.CountUnsentMessages(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, count)
+
+	// Varsayılan bölge olmadan yalnızca uluslararası numaralar kabul edilir
+	service = NewMessageService(suite.config, messageRepo, nil, suite.messageClient)
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "0532 123 45 67", Content: "hello"})
+	assert.ErrorIs(suite.T(), err, ErrInvalidMessage)
+}
+
+// TestContentLimits, içerik sınırlarının bayt yerine karakter ve segment
+// olarak uygulandığını test eder
+func (suite *MessageServiceTestSuite) TestContentLimits() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	cfg := *suite.config
+	cfg.App.MaxContentLength = 100
+	cfg.App.MaxSegments = 1
+	service := NewMessageService(&cfg, messageRepo, nil, suite.messageClient)
+
+	// 70 "ş" 140 bayt tutar ama tek UCS-2 segmentine sığar
+	msg, err := service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: strings.Repeat("ş", 70)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "UCS-2", msg.Encoding)
+	assert.Equal(suite.T(), 1, msg.Segments)
+
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: strings.Repeat("ş", 71)})
+	assert.ErrorIs(suite.T(), err, ErrInvalidMessage)
+	assert.ErrorContains(suite.T(), err, "maximum of 1 segments")
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: strings.Repeat("a", 101)})
+	assert.ErrorContains(suite.T(), err, "maximum length of 100 characters")
+
+	preview := service.PreviewContent(strings.Repeat("a", 90)+"ş", "")
+	assert.Equal(suite.T(), models.ContentPreview{
+		Encoding:         "UCS-2",
+		Characters:       91,
+		Units:            91,
+		Segments:         2,
+		UnitsPerSegment:  67,
+		Remaining:        43,
+		NonGSMCharacters: []string{"ş"},
+		MaxCharacters:    100,
+		MaxSegments:      1,
+		Error:            "content exceeds maximum of 1 segments",
+	}, preview)
+
+	preview = service.PreviewContent("Merhaba", "")
+	assert.True(suite.T(), preview.Valid)
+	assert.Equal(suite.T(), "GSM-7", preview.Encoding)
+	assert.Equal(suite.T(), 153, preview.Remaining)
+
+	// Veritabanına doğrudan eklenen uzun mesajlar gönderilmeden başarısız olur
+	id, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: strings.Repeat("ğ", 80)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1, Skipped: 1}, service.RunOnce(ctx))
+	msg, err = messageRepo.GetMessage(ctx, id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageStatusFailed, msg.Status())
+	assert.Equal(suite.T(), "message content exceeds maximum of 1 segments", msg.LastError)
+}
+
+// TestTransliteration, içeriğin GSM-7'ye sığacak şekilde harf çevirisiyle
+// gönderildiğini ve asıl içeriğin saklandığını test eder
+func (suite *MessageServiceTestSuite) TestTransliteration() {
+	var mu sync.Mutex
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request models.MessageRequest
+		assert.NoError(suite.T(), json.NewDecoder(r.Body).Decode(&request))
+		mu.Lock()
+		sent = append(sent, request.Content)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"ext"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	cfg := *suite.config
+	cfg.App.MessageBatchSize = 10
+	cfg.App.MaxSegments = 1
+	cfg.App.Transliteration = "tr"
+	service := NewMessageService(&cfg, messageRepo, nil, clients.NewMessageClient(server.URL, false))
+
+	// Varsayılan dilde çevrilen içerik GSM-7 olarak sayılır ama olduğu gibi saklanır
+	content := "Teşekkürler, siparişiniz yolda"
+	translated, err := service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: content})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), content, translated.Content)
+	assert.Equal(suite.T(), "GSM-7", translated.Encoding)
+
+	// 100 "ş" yalnızca çevrildiğinde tek segmente sığar
+	long := strings.Repeat("ş", 100)
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: long})
+	assert.NoError(suite.T(), err)
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: long, Transliteration: models.MessageTransliterationOff})
+	assert.ErrorContains(suite.T(), err, "maximum of 1 segments")
+
+	// Mesaj bazında kapatılan çeviri ve çevrilemeyen emoji içeriği değiştirmez
+	unchanged, err := service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: "Şişli", Transliteration: "OFF"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageTransliterationOff, unchanged.Transliteration)
+	assert.Equal(suite.T(), "UCS-2", unchanged.Encoding)
+	emoji, err := service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: "Teşekkürler 👋"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "UCS-2", emoji.Encoding)
+
+	_, err = service.CreateMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: "hello", Transliteration: "tlh"})
+	assert.ErrorIs(suite.T(), err, ErrInvalidMessage)
+
+	// Çevirinin değiştirilmesi kodlamayı da günceller
+	off := models.MessageTransliterationOff
+	updated, err := service.UpdateMessage(ctx, translated.ID, repository.MessageUpdate{Transliteration: &off})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "UCS-2", updated.Encoding)
+	turkish := "tr"
+	updated, err = service.UpdateMessage(ctx, translated.ID, repository.MessageUpdate{Transliteration: &turkish})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "GSM-7", updated.Encoding)
+
+	assert.Equal(suite.T(), models.RunSummary{Sent: 4}, service.RunOnce(ctx))
+	assert.ElementsMatch(suite.T(), []string{"Tesekkürler, siparisiniz yolda", strings.Repeat("s", 100), "Şişli", "Teşekkürler 👋"}, sent)
+
+	msg, err := messageRepo.GetMessage(ctx, translated.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageStatusSent, msg.Status())
+	assert.Equal(suite.T(), content, msg.Content, "Asıl içerik denetim için saklanmalı")
+
+	// Önizleme gönderilecek içeriği gösterir
+	preview := service.PreviewContent("Şişli", "")
+	assert.Equal(suite.T(), "Sisli", preview.Transliterated)
+	assert.Equal(suite.T(), "GSM-7", preview.Encoding)
+	assert.True(suite.T(), preview.Valid)
+	preview = service.PreviewContent("Şişli", models.MessageTransliterationOff)
+	assert.Empty(suite.T(), preview.Transliterated)
+	assert.Equal(suite.T(), "UCS-2", preview.Encoding)
+	preview = service.PreviewContent("Şişli", "tlh")
+	assert.False(suite.T(), preview.Valid)
+	assert.Contains(suite.T(), preview.Error, "transliteration")
+}
+
+// TestCancelMessage, iptal edilen mesajın güncel haliyle döndürüldüğünü test eder
+func (suite *MessageServiceTestSuite) TestCancelMessage() {
+	ctx := context.Background()
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, suite.messageClient)
+
+	cancelledAt := time.Now()
+	suite.mockMsgRepo.EXPECT().CancelMessage(mock.Anything, 1).Return(nil).Once()
+	suite.mockMsgRepo.EXPECT().GetMessage(mock.Anything, 1).Return(models.Message{ID: 1, CancelledAt: &cancelledAt}, nil).Once()
+
+	msg, err := service.CancelMessage(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageStatusCancelled, msg.Status())
+
+	suite.mockMsgRepo.EXPECT().CancelMessage(mock.Anything, 2).Return(repository.ErrMessageNotFound).Once()
+	_, err = service.CancelMessage(ctx, 2)
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageNotFound)
+}
+
+// TestResendMessage, yeniden gönderimin bağlı bir kopya oluşturup servis gönderim yapıyorsa hemen
+// gönderdiğini, aksi halde yüksek öncelikle kuyruğa aldığını test eder
+func (suite *MessageServiceTestSuite) TestResendMessage() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	original, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: "hello"})
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), messageRepo.MarkMessageAsSent(ctx, original, "ext-1"))
+	var unsent []int
+	for _, content := range []string{"first", "second"} {
+		id, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: content})
+		assert.NoError(suite.T(), err)
+		unsent = append(unsent, id)
+	}
+	assertStatus := func(statuses map[int]string) {
+		for id, status := range statuses {
+			msg, err := messageRepo.GetMessage(ctx, id)
+			assert.NoError(suite.T(), err)
+			assert.Equal(suite.T(), status, msg.Status(), "message %d", id)
+		}
+	}
+
+	// Her gün 09:00-21:00
+	window, err := schedule.ParseWindow("09:00", "21:00", nil, "")
+	assert.NoError(suite.T(), err)
+	fakeClock := clock.NewFake(time.Date(2024, 5, 15, 20, 58, 0, 0, time.UTC))
+	service := NewMessageService(suite.config, messageRepo, nil, suite.messageClient,
+		WithClock(fakeClock), WithSendingWindows(schedule.Windows{window}))
+
+	assertQueued := func(msg models.Message) {
+		assert.Equal(suite.T(), models.MessageStatusUnsent, msg.Status())
+		assert.Equal(suite.T(), models.MessagePriorityHigh, msg.Priority)
+		assert.Nil(suite.T(), msg.ClaimedAt)
+		assert.Equal(suite.T(), &original, msg.ParentID)
+	}
+
+	// Durdurulmuş serviste kopya gönderilmez, kuyruğa alınır
+	stopped, err := service.ResendMessage(ctx, original)
+	assert.NoError(suite.T(), err)
+	assertQueued(stopped)
+
+	// Başlangıçtaki çalıştırma kuyruktaki kopyayı diğer mesajlardan önce gönderir
+	assert.NoError(suite.T(), service.Start())
+	defer service.Stop(context.Background())
+	fakeClock.WaitForTimers(1)
+	assertStatus(map[int]string{stopped.ID: models.MessageStatusSent, unsent[0]: models.MessageStatusSent, unsent[1]: models.MessageStatusUnsent})
+
+	// Kopya zamanlayıcıyı beklemeden gönderilmeli
+	resend, err := service.ResendMessage(ctx, original)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), original, resend.ID)
+	assert.Equal(suite.T(), models.MessageStatusSent, resend.Status())
+	assert.Equal(suite.T(), "hello", resend.Content)
+	assert.Equal(suite.T(), models.MessagePriorityNormal, resend.Priority)
+	assert.Equal(suite.T(), &original, resend.ParentID)
+
+	// Bir kopyanın yeniden gönderimi de orijinal mesaja bağlanır
+	again, err := service.ResendMessage(ctx, resend.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageStatusSent, again.Status())
+	assert.Equal(suite.T(), &original, again.ParentID)
+
+	// Duraklatılmış serviste ve gönderim penceresi dışında kopya kuyrukta bekler
+	assert.NoError(suite.T(), service.Pause())
+	paused, err := service.ResendMessage(ctx, original)
+	assert.NoError(suite.T(), err)
+	assertQueued(paused)
+	assert.NoError(suite.T(), service.Resume())
+
+	fakeClock.Set(time.Date(2024, 5, 15, 21, 1, 0, 0, time.UTC))
+	fakeClock.WaitForTimers(1)
+	outside, err := service.ResendMessage(ctx, original)
+	assert.NoError(suite.T(), err)
+	assertQueued(outside)
+
+	resends, err := service.GetResends(ctx, original)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []int{stopped.ID, resend.ID, again.ID, paused.ID, outside.ID}, []int{resends[0].ID, resends[1].ID, resends[2].ID, resends[3].ID, resends[4].ID})
+
+	// Pencere açıldığında kuyruktaki kopyalar yine önce gönderilir
+	fakeClock.Set(time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC))
+	fakeClock.WaitForTimers(1)
+	assertStatus(map[int]string{paused.ID: models.MessageStatusSent, outside.ID: models.MessageStatusSent, unsent[1]: models.MessageStatusUnsent})
+
+	// Henüz gönderilmemiş mesaj yeniden gönderilemez
+	_, err = service.ResendMessage(ctx, unsent[1])
+	assert.ErrorIs(suite.T(), err, ErrMessageNotResendable)
+	_, err = service.ResendMessage(ctx, 999)
+	assert.ErrorIs(suite.T(), err, repository.ErrMessageNotFound)
+
+	assert.Equal(suite.T(), 6, service.GetStatus(ctx).Total.Sent)
+}
+
+// TestRunOnce, anlık çalıştırmanın zamanlayıcıyı başlatmadan mesajları gönderdiğini test eder
+func (suite *MessageServiceTestSuite) TestRunOnce() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	for _, content := range []string{"first", "second", "third"} {
+		_, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: content})
+		assert.NoError(suite.T(), err)
+	}
+
+	service := NewMessageService(suite.config, messageRepo, nil, suite.messageClient)
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2}, service.RunOnce(ctx))
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1}, service.RunOnce(ctx))
+	assert.Equal(suite.T(), models.RunSummary{}, service.RunOnce(ctx))
+	assert.False(suite.T(), service.Status())
+
+	status := service.GetStatus(ctx)
+	assert.Equal(suite.T(), models.RunSummary{Sent: 3}, status.Total)
+	assert.NotNil(suite.T(), status.LastRunTime)
+	assert.Nil(suite.T(), status.NextRunTime)
+}
+
+// TestRunOnceWaitsForRunningBatch, anlık çalıştırmanın devam eden batch ile çakışmadığını test eder
+func (suite *MessageServiceTestSuite) TestRunOnceWaitsForRunningBatch() {
+	received := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"ext-2"}`))
+	}))
+	defer server.Close()
+
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return(suite.unsentMessages, nil).Once()
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).Return([]models.Message{}, nil)
+	suite.mockMsgRepo.EXPECT().MarkMessageAsSent(mock.Anything, 2, "ext-2").Return(nil).Once()
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, "ext-2", 2, mock.AnythingOfType("time.Time")).Return(nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil)
+
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, clients.NewMessageClient(server.URL, false))
+	assert.NoError(suite.T(), service.Start())
+	<-received
+
+	summary := make(chan models.RunSummary, 1)
+	go func() {
+		summary <- service.RunOnce(context.Background())
+	}()
+
+	// Zamanlanmış batch bitmeden anlık çalıştırma başlamamalı
+	select {
+	case <-summary:
+		suite.T().Fatal("RunOnce devam eden batch bitmeden dönmemeli")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(suite.T(), models.RunSummary{}, <-summary, "Mesaj zaten gönderildiği için anlık çalıştırma boş dönmeli")
+	assert.NoError(suite.T(), service.Stop(context.Background()))
+}
+
+// TestRunOnceOutlivesRequestDeadline, anlık çalıştırmanın isteğin süresi dolduktan sonra da batch'i tamamladığını test eder
+func (suite *MessageServiceTestSuite) TestRunOnceOutlivesRequestDeadline() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Accepted","messageId":"ext-1"}`))
+	}))
+	defer server.Close()
+
+	messageRepo := repository.NewMemoryRepository()
+	for _, content := range []string{"first", "second"} {
+		_, err := messageRepo.AddMessage(context.Background(), models.Message{PhoneNumber: "+90123456789", Content: content})
+		assert.NoError(suite.T(), err)
+	}
+
+	// İsteğin süresi ilk gönderim bitmeden dolar
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	service := NewMessageService(suite.config, messageRepo, nil, clients.NewMessageClient(server.URL, false))
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2}, service.RunOnce(ctx))
+	assert.Error(suite.T(), ctx.Err())
+
+	status := service.GetStatus(context.Background())
+	assert.Empty(suite.T(), status.LastError)
+	assert.Equal(suite.T(), 0, *status.QueueDepth)
+}
+
+// TestPauseResumeDrain, duraklatma, devam ettirme ve kuyruk boşaltma modlarını test eder
+func (suite *MessageServiceTestSuite) TestPauseResumeDrain() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	for i := 0; i < 7; i++ {
+		_, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: fmt.Sprintf("message %d", i)})
+		assert.NoError(suite.T(), err)
+	}
+	unsent := func() int {
+		count, err := messageRepo.CountUnsentMessages(ctx)
+		assert.NoError(suite.T(), err)
+		return count
+	}
+
+	service := NewMessageService(suite.config, messageRepo, nil, suite.messageClient).(*MessageService)
+
+	// Çalışmayan servis duraklatılamaz ya da boşaltılamaz
+	assert.Error(suite.T(), service.Pause())
+	assert.Error(suite.T(), service.Resume())
+	assert.Error(suite.T(), service.Drain())
+
+	// Başlangıçta ilk batch hemen gönderilir
+	assert.NoError(suite.T(), service.Start())
+	assert.Eventually(suite.T(), func() bool { return unsent() == 5 }, time.Second, 5*time.Millisecond)
+	assert.Equal(suite.T(), models.ServiceModeRunning, service.GetStatus(ctx).Mode)
+
+	// Duraklatılmış servis zamanlanmış çalıştırmaları atlar
+	assert.NoError(suite.T(), service.Pause())
+	assert.Error(suite.T(), service.Pause())
+	service.scheduledRun(ctx, nil)
+	assert.Equal(suite.T(), 5, unsent())
+	status := service.GetStatus(ctx)
+	assert.True(suite.T(), status.IsRunning)
+	assert.Equal(suite.T(), models.ServiceModePaused, status.Mode)
+
+	assert.NoError(suite.T(), service.Resume())
+	service.scheduledRun(ctx, nil)
+	assert.Equal(suite.T(), 3, unsent())
+
+	// Boşaltma kuyruk bitene kadar aralığı beklemeden batch gönderir, sonra normal akışa döner
+	assert.NoError(suite.T(), service.Drain())
+	assert.Eventually(suite.T(), func() bool {
+		return service.Mode() == models.ServiceModeRunning
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(suite.T(), 0, unsent())
+	status = service.GetStatus(ctx)
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1}, status.LastRun)
+	assert.Equal(suite.T(), 7, status.Total.Sent)
+	assert.NotNil(suite.T(), status.NextRunTime)
+
+	assert.NoError(suite.T(), service.Stop(context.Background()))
+	assert.Equal(suite.T(), models.ServiceModeStopped, service.GetStatus(ctx).Mode)
+}
+
+// TestDrainStopsWhenNothingIsSent, webhook hata verirken boşaltmanın sonsuz döngüye girmediğini test eder
+func (suite *MessageServiceTestSuite) TestDrainStopsWhenNothingIsSent() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	for i := 0; i < 3; i++ {
+		_, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: "hello"})
+		assert.NoError(suite.T(), err)
+	}
+
+	service := NewMessageService(suite.config, messageRepo, nil,
+		clients.NewMessageClient(server.URL, false)).(*MessageService)
+	assert.NoError(suite.T(), service.Start())
+	assert.NoError(suite.T(), service.Drain())
+	assert.Eventually(suite.T(), func() bool {
+		return service.Mode() == models.ServiceModeRunning
+	}, 2*time.Second, 5*time.Millisecond)
+
+	count, err := messageRepo.CountUnsentMessages(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, count)
+	assert.NoError(suite.T(), service.Stop(context.Background()))
+}
+
+// TestSendingWindows, pencere dışındaki çalıştırmaların pencere açılana kadar bekletildiğini test eder
+func (suite *MessageServiceTestSuite) TestSendingWindows() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	for i := 0; i < 6; i++ {
+		_, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: fmt.Sprintf("message %d", i)})
+		assert.NoError(suite.T(), err)
+	}
+	unsent := func() int {
+		count, err := messageRepo.CountUnsentMessages(ctx)
+		assert.NoError(suite.T(), err)
+		return count
+	}
+
+	// Pazartesi-cumartesi 09:00-21:00, cumartesi akşamından başlanıyor
+	window, err := schedule.ParseWindow("09:00", "21:00", []string{"mon-sat"}, "Europe/Istanbul")
+	assert.NoError(suite.T(), err)
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	assert.NoError(suite.T(), err)
+	fakeClock := clock.NewFake(time.Date(2024, 5, 18, 20, 57, 0, 0, istanbul))
+
+	service := NewMessageService(suite.config, messageRepo, nil, suite.messageClient,
+		WithClock(fakeClock), WithSendingWindows(schedule.Windows{window}))
+	assert.NoError(suite.T(), service.Start())
+	defer service.Stop(context.Background())
+
+	// İlk çalıştırma pencere içinde, bir sonraki de aralık kadar sonra
+	fakeClock.WaitForTimers(1)
+	assert.Equal(suite.T(), 4, unsent())
+	assert.True(suite.T(), time.Date(2024, 5, 18, 20, 59, 0, 0, istanbul).Equal(*service.GetStatus(ctx).NextRunTime))
+
+	// 21:01'deki çalıştırma pazar atlanarak pazartesi 09:00'a ertelenir
+	fakeClock.Advance(2 * time.Minute)
+	fakeClock.WaitForTimers(1)
+	assert.Equal(suite.T(), 2, unsent())
+	monday := time.Date(2024, 5, 20, 9, 0, 0, 0, istanbul)
+	assert.True(suite.T(), monday.Equal(*service.GetStatus(ctx).NextRunTime))
+	assert.True(suite.T(), monday.Equal(fakeClock.Timers()[0]))
+
+	fakeClock.Set(time.Date(2024, 5, 19, 12, 0, 0, 0, istanbul))
+	assert.Equal(suite.T(), 2, unsent(), "Pencere dışında mesaj gönderilmemeli")
+
+	fakeClock.Set(monday)
+	fakeClock.WaitForTimers(1)
+	assert.Equal(suite.T(), 0, unsent())
+	assert.True(suite.T(), monday.Add(2*time.Minute).Equal(*service.GetStatus(ctx).NextRunTime))
+}
+
+// TestQuietHours, promosyon mesajlarının alıcının sessiz saatleri bitene kadar ertelendiğini test eder
+func (suite *MessageServiceTestSuite) TestQuietHours() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	promotional, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: "sale", Category: models.MessageCategoryPromotional})
+	assert.NoError(suite.T(), err)
+	transactional, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+905321234567", Content: "code"})
+	assert.NoError(suite.T(), err)
+
+	quietHours, err := recipient.NewQuietHours("21:00", "09:00", "")
+	assert.NoError(suite.T(), err)
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	assert.NoError(suite.T(), err)
+	fakeClock := clock.NewFake(time.Date(2024, 5, 15, 3, 0, 0, 0, istanbul))
+
+	// Gece 03:00'te işlem mesajı gönderilir, promosyon mesajı 09:00'a ertelenir;
+	// gönderim zamanı servisin saatinden alınır
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, mock.Anything, transactional, fakeClock.Now()).Return(nil).Once()
+	service := NewMessageService(suite.config, messageRepo, suite.mockCacheRepo, suite.messageClient,
+		WithClock(fakeClock), WithQuietHours(quietHours))
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1, Deferred: 1}, service.RunOnce(ctx))
+
+	msg, err := messageRepo.GetMessage(ctx, transactional)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MessageStatusSent, msg.Status())
+
+	msg, err = messageRepo.GetMessage(ctx, promotional)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), msg.IsSent)
+	assert.Nil(suite.T(), msg.ClaimedAt)
+	if assert.NotNil(suite.T(), msg.ScheduledAt) {
+		assert.True(suite.T(), time.Date(2024, 5, 15, 9, 0, 0, 0, istanbul).Equal(*msg.ScheduledAt))
+	}
+
+	// Sessiz saatler dışında promosyon mesajı hemen gönderilir
+	fakeClock.Set(time.Date(2024, 5, 15, 9, 0, 0, 0, istanbul))
+	suite.mockCacheRepo.EXPECT().CacheMessageID(mock.Anything, mock.Anything, promotional, fakeClock.Now()).Return(nil).Once()
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1}, service.RunOnce(ctx))
+}
+
+// TestPriority, yüksek öncelikli mesajların önce gönderildiğini ve düşük
+// öncelikli mesajların tamamen bekletilmediğini test eder
+func (suite *MessageServiceTestSuite) TestPriority() {
+	ctx := context.Background()
+	messageRepo := repository.NewMemoryRepository()
+	add := func(content string, priority int) int {
+		id, err := messageRepo.AddMessage(ctx, models.Message{PhoneNumber: "+90123456789", Content: content, Priority: priority})
+		assert.NoError(suite.T(), err)
+		return id
+	}
+	campaign := []int{add("sale 1", models.MessagePriorityLow), add("sale 2", models.MessagePriorityLow)}
+	otps := []int{add("otp 1", models.MessagePriorityHigh), add("otp 2", models.MessagePriorityHigh), add("otp 3", models.MessagePriorityHigh)}
+	sent := func(id int) bool {
+		msg, err := messageRepo.GetMessage(ctx, id)
+		assert.NoError(suite.T(), err)
+		return msg.IsSent
+	}
+
+	// Batch boyutu 2, payın yarısı yüksek önceliğe ayrılmış
+	cfg := *suite.config
+	cfg.App.HighPriorityShare = 0.5
+	service := NewMessageService(&cfg, messageRepo, nil, suite.messageClient)
+
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2}, service.RunOnce(ctx))
+	assert.True(suite.T(), sent(otps[0]), "En eski yüksek öncelikli mesaj ayrılan paydan gönderilmeli")
+	assert.True(suite.T(), sent(campaign[0]), "Düşük öncelikli mesaj kalan paydan gönderilmeli")
+	assert.False(suite.T(), sent(otps[1]))
+
+	assert.Equal(suite.T(), models.RunSummary{Sent: 2}, service.RunOnce(ctx))
+	assert.True(suite.T(), sent(otps[1]))
+	assert.True(suite.T(), sent(campaign[1]))
+
+	// Düşük öncelikli mesaj kalmayınca batch yüksek öncelikle dolar
+	assert.Equal(suite.T(), models.RunSummary{Sent: 1}, service.RunOnce(ctx))
+	assert.True(suite.T(), sent(otps[2]))
+}
+
+// TestReservedSlots, batch içinde yüksek önceliğe ayrılan yer sayısını test eder
+func TestReservedSlots(t *testing.T) {
+	for _, tc := range []struct {
+		batchSize int
+		share     float64
+		want      int
+	}{
+		{10, 0.8, 8},
+		// Varsayılan ayarlarla da düşük öncelikler için bir yer kalmalı
+		{2, 0.8, 1},
+		{2, 0.5, 1},
+		{3, 0.5, 2},
+		{10, 0.99, 9},
+		{5, 0.01, 1},
+		{1, 0.8, 1},
+		{1, 0, 0},
+		{5, 0, 0},
+		{5, -1, 0},
+		{5, 1, 5},
+		{5, 1.5, 5},
+	} {
+		assert.Equal(t, tc.want, reservedSlots(tc.batchSize, tc.share), "batch %d, share %v", tc.batchSize, tc.share)
+	}
+}
+
+// TestCronSchedule, cron ifadesiyle zamanlanan çalıştırmaları test eder
+func (suite *MessageServiceTestSuite) TestCronSchedule() {
+	ctx := context.Background()
+	cron, err := schedule.ParseCron("0 */6 * * *", time.UTC)
+	assert.NoError(suite.T(), err)
+	fakeClock := clock.NewFake(time.Date(2024, 5, 15, 7, 30, 0, 0, time.UTC))
+
+	service := NewMessageService(suite.config, repository.NewMemoryRepository(), nil, suite.messageClient,
+		WithClock(fakeClock), WithSchedule(cron))
+	assert.NoError(suite.T(), service.Start())
+	defer service.Stop(context.Background())
+
+	fakeClock.WaitForTimers(1)
+	assert.Equal(suite.T(), []time.Time{time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)}, fakeClock.Timers())
+
+	fakeClock.Advance(5 * time.Hour)
+	fakeClock.WaitForTimers(1)
+	assert.Equal(suite.T(), []time.Time{time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC)}, fakeClock.Timers())
+	assert.Equal(suite.T(), time.Date(2024, 5, 15, 12, 30, 0, 0, time.UTC), *service.GetStatus(ctx).LastRunTime)
+}
+
+// neverSchedule, hiç çalışma zamanı olmayan bir zamanlama
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time { return time.Time{} }
+
+// TestScheduleWithoutFurtherRuns, çalışma zamanı kalmayan zamanlamada
+// döngünün art arda işlem yapmadığını test eder
+func (suite *MessageServiceTestSuite) TestScheduleWithoutFurtherRuns() {
+	var runs atomic.Int32
+	suite.mockMsgRepo.EXPECT().ClaimUnsentMessages(mock.Anything, suite.config.App.MessageBatchSize, suite.config.App.MessageBatchSize).
+		Run(func(context.Context, int, int) { runs.Add(1) }).Return([]models.Message{}, nil)
+	suite.mockMsgRepo.EXPECT().CountUnsentMessages(mock.Anything).Return(0, nil).Maybe()
+	fakeClock := clock.NewFake(time.Date(2024, 5, 15, 7, 30, 0, 0, time.UTC))
+
+	service := NewMessageService(suite.config, suite.mockMsgRepo, suite.mockCacheRepo, suite.messageClient,
+		WithClock(fakeClock), WithSchedule(neverSchedule{}))
+	assert.NoError(suite.T(), service.Start())
+
+	// Yalnızca başlangıçtaki çalışma yapılmalı, zamanlayıcı kurulmamalı
+	assert.Eventually(suite.T(), func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(suite.T(), int32(1), runs.Load())
+	assert.Empty(suite.T(), fakeClock.Timers())
+	assert.Nil(suite.T(), service.GetStatus(context.Background()).NextRunTime)
+
+	assert.NoError(suite.T(), service.Stop(context.Background()))
+}
+
 // TestMessageServiceSuite çalıştırma fonksiyonu
 func TestMessageServiceSuite(t *testing.T) {
 	suite.Run(t, new(MessageServiceTestSuite))
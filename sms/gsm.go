@@ -0,0 +1,32 @@
+package sms
+
+// gsmBasic is the GSM 03.38 default alphabet; each character takes one septet
+var gsmBasic = setOf("@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà")
+
+// gsmExtension is the GSM 03.38 extension table; its characters are escaped
+// and take two septets
+var gsmExtension = setOf("\f^{}\\[~]|€")
+
+// setOf returns the runes of s as a set
+func setOf(s string) map[rune]bool {
+	set := make(map[rune]bool, len(s))
+	for _, r := range s {
+		set[r] = true
+	}
+	return set
+}
+
+// IsGSM reports whether r can be sent in the GSM 7-bit alphabet, including
+// the extension table
+func IsGSM(r rune) bool {
+	return gsmBasic[r] || gsmExtension[r]
+}
+
+// septets returns the number of septets r takes in the GSM 7-bit alphabet
+func septets(r rune) int {
+	if gsmExtension[r] {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,97 @@
+// Package sms detects the encoding text is sent in as SMS and counts the
+// segments it is split into.
+package sms
+
+// Encoding is the character encoding of an SMS
+type Encoding string
+
+const (
+	// GSM7 packs characters of the GSM 03.38 alphabet into 7 bits
+	GSM7 Encoding = "GSM-7"
+	// UCS2 is used as soon as a single character is not in the GSM alphabet
+	UCS2 Encoding = "UCS-2"
+)
+
+// Segment sizes in encoding units: septets for GSM-7, UTF-16 code units for
+// UCS-2. Concatenated messages lose room to the user data header.
+const (
+	gsmSingle  = 160
+	gsmConcat  = 153
+	ucs2Single = 70
+	ucs2Concat = 67
+)
+
+// Info describes how text is sent as SMS
+type Info struct {
+	Encoding   Encoding
+	Characters int // Unicode characters
+	Units      int // septets for GSM-7, UTF-16 code units for UCS-2
+	Segments   int
+	// UnitsPerSegment is the size of a segment, smaller once the text is
+	// split into several segments
+	UnitsPerSegment int
+	// Remaining is the number of units left in the last segment
+	Remaining int
+	// NonGSM lists the distinct characters that force UCS-2, in order of appearance
+	NonGSM []string
+}
+
+// Analyze detects the encoding of text and counts its segments. An escaped
+// GSM character or a surrogate pair is never split across segments.
+func Analyze(text string) Info {
+	info := Info{Encoding: GSM7}
+	seen := map[rune]bool{}
+	for _, r := range text {
+		info.Characters++
+		if !IsGSM(r) && !seen[r] {
+			seen[r] = true
+			info.Encoding = UCS2
+			info.NonGSM = append(info.NonGSM, string(r))
+		}
+	}
+
+	width, single, concat := septets, gsmSingle, gsmConcat
+	if info.Encoding == UCS2 {
+		width, single, concat = utf16Units, ucs2Single, ucs2Concat
+	}
+
+	for _, r := range text {
+		info.Units += width(r)
+	}
+
+	switch {
+	case info.Units == 0:
+		info.UnitsPerSegment, info.Remaining = single, single
+	case info.Units <= single:
+		info.Segments, info.UnitsPerSegment, info.Remaining = 1, single, single-info.Units
+	default:
+		// Fill the segments in order, moving a character that doesn't fit
+		// completely to the next one
+		info.Segments, info.UnitsPerSegment = 1, concat
+		used := 0
+		for _, r := range text {
+			w := width(r)
+			if used+w > concat {
+				info.Segments++
+				used = 0
+			}
+			used += w
+		}
+		info.Remaining = concat - used
+	}
+	return info
+}
+
+// Segments returns the number of segments text is sent in
+func Segments(text string) int {
+	return Analyze(text).Segments
+}
+
+// utf16Units returns the number of UTF-16 code units r takes, two for
+// characters outside the Basic Multilingual Plane such as most emoji
+func utf16Units(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
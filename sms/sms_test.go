@@ -0,0 +1,81 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		encoding  Encoding
+		units     int
+		segments  int
+		remaining int
+	}{
+		{"boş", "", GSM7, 0, 0, 160},
+		{"gsm", "Hello, world!", GSM7, 13, 1, 147},
+		{"gsm tek segment sınırı", strings.Repeat("a", 160), GSM7, 160, 1, 0},
+		{"gsm iki segment", strings.Repeat("a", 161), GSM7, 161, 2, 145},
+		{"gsm üç segment", strings.Repeat("a", 307), GSM7, 307, 3, 152},
+		// Genişletme tablosundaki karakterler iki septet tutar
+		{"gsm genişletme", "Price: 5€ {a}", GSM7, 16, 1, 144},
+		{"gsm genişletme sınırı", strings.Repeat("€", 80), GSM7, 160, 1, 0},
+		// Ç ve ö GSM alfabesinde, ş değil
+		{"gsm türkçe", "Çok güzel", GSM7, 9, 1, 151},
+		{"ucs2", "Kargonuz yolda, teşekkürler", UCS2, 27, 1, 43},
+		{"ucs2 tek segment sınırı", strings.Repeat("ş", 70), UCS2, 70, 1, 0},
+		{"ucs2 iki segment", strings.Repeat("ş", 71), UCS2, 71, 2, 63},
+		// Emoji iki UTF-16 birimi tutar
+		{"ucs2 emoji", "Hi 👋", UCS2, 5, 1, 65},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Analyze(tt.text)
+			assert.Equal(t, tt.encoding, info.Encoding)
+			assert.Equal(t, tt.units, info.Units)
+			assert.Equal(t, tt.segments, info.Segments)
+			assert.Equal(t, tt.remaining, info.Remaining)
+		})
+	}
+}
+
+func TestAnalyzeDoesNotSplitCharacters(t *testing.T) {
+	// 152 septet ve bir escape karakteri ilk segmente sığmaz
+	info := Analyze(strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10))
+	assert.Equal(t, 164, info.Units)
+	assert.Equal(t, 2, info.Segments)
+	assert.Equal(t, 153-12, info.Remaining)
+
+	// Aynı şekilde surrogate çiftleri de bölünmez
+	info = Analyze(strings.Repeat("ş", 66) + "👋" + strings.Repeat("ş", 5))
+	assert.Equal(t, 73, info.Units)
+	assert.Equal(t, 2, info.Segments)
+	assert.Equal(t, 67-7, info.Remaining)
+}
+
+func TestNonGSM(t *testing.T) {
+	info := Analyze("Şişli'de şimdi ığdır")
+	assert.Equal(t, UCS2, info.Encoding)
+	assert.Equal(t, []string{"Ş", "ş", "ı", "ğ"}, info.NonGSM)
+	assert.Equal(t, 20, info.Characters)
+
+	assert.Empty(t, Analyze("Ödeme {tamam}").NonGSM)
+}
+
+func TestIsGSM(t *testing.T) {
+	for _, r := range "@£$¥\n\rΔ_ÆßÉ!?¡§¿AZaz09äöñüà^{}\\[~]|€\f" {
+		assert.True(t, IsGSM(r), string(r))
+	}
+	for _, r := range "şğıİ`✓👋çÁ" {
+		assert.False(t, IsGSM(r), string(r))
+	}
+}
+
+func TestSegments(t *testing.T) {
+	assert.Equal(t, 1, Segments("hello"))
+	assert.Equal(t, 3, Segments(strings.Repeat("ü", 307)))
+}
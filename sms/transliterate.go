@@ -0,0 +1,58 @@
+package sms
+
+import "strings"
+
+// transliterations maps languages to GSM equivalents of their letters that
+// are missing from the GSM alphabet. Letters the alphabet has, such as Ç, ö
+// or ü, are kept.
+var transliterations = map[string]map[rune]string{
+	"tr": {
+		'ç': "c", 'ğ': "g", 'Ğ': "G", 'ı': "i", 'İ': "I", 'ş': "s", 'Ş': "S",
+		'â': "a", 'Â': "A", 'î': "i", 'Î': "I", 'û': "u", 'Û': "U",
+	},
+	"es": {
+		'á': "a", 'Á': "A", 'í': "i", 'Í': "I", 'ó': "o", 'Ó': "O", 'ú': "u", 'Ú': "U",
+	},
+	"ro": {
+		'ă': "a", 'Ă': "A", 'â': "a", 'Â': "A", 'î': "i", 'Î': "I",
+		'ș': "s", 'Ș': "S", 'ş': "s", 'Ş': "S", 'ț': "t", 'Ț': "T", 'ţ': "t", 'Ţ': "T",
+	},
+}
+
+// punctuation holds typographic characters that word processors put in
+// place of their GSM equivalents, replaced in every language
+var punctuation = map[rune]string{
+	'‘': "'", '’': "'", '‚': "'", '“': "\"", '”': "\"", '„': "\"",
+	'–': "-", '—': "-", '…': "...", ' ': " ",
+}
+
+// SupportedLanguage reports whether text in the language can be
+// transliterated. Language codes are ISO 639-1 and case-insensitive.
+func SupportedLanguage(language string) bool {
+	_, ok := transliterations[strings.ToLower(language)]
+	return ok
+}
+
+// Transliterate replaces the characters of text that are missing from the GSM
+// alphabet with their equivalents in the language. ok reports whether the
+// result can be sent in GSM-7; when it can't, text is returned unchanged, as
+// removing some of the accents would not make the message any cheaper.
+func Transliterate(text, language string) (string, bool) {
+	table := transliterations[strings.ToLower(language)]
+	var result strings.Builder
+	for _, r := range text {
+		if IsGSM(r) {
+			result.WriteRune(r)
+			continue
+		}
+		replacement, found := punctuation[r]
+		if !found {
+			replacement, found = table[r]
+		}
+		if !found {
+			return text, false
+		}
+		result.WriteString(replacement)
+	}
+	return result.String(), true
+}
@@ -0,0 +1,62 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		result   string
+		ok       bool
+	}{
+		{"türkçe", "Şişli'de ığdır çayı", "tr", "Sisli'de igdir cayi", true},
+		// Ç, ö ve ü GSM alfabesinde olduğu için korunur
+		{"türkçe gsm harfleri", "Çok güzel, öğretmen", "tr", "Çok güzel, ögretmen", true},
+		{"büyük küçük harf duyarsız dil kodu", "İstanbul", "TR", "Istanbul", true},
+		{"romence", "Mulțumim, ștampilă", "ro", "Multumim, stampila", true},
+		{"ispanyolca", "Información útil", "es", "Informacion util", true},
+		// Tipografik noktalama her dilde değiştirilir
+		{"noktalama", "“Teşekkürler” – ekip…", "tr", "\"Tesekkürler\" - ekip...", true},
+		{"zaten gsm", "Hello {world}", "tr", "Hello {world}", true},
+		// Karşılığı olmayan bir karakter kalırsa metin olduğu gibi döner
+		{"emoji", "Teşekkürler 👋", "tr", "Teşekkürler 👋", false},
+		{"başka dilin harfleri", "Şişli", "es", "Şişli", false},
+		{"bilinmeyen dil", "Şişli", "xx", "Şişli", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := Transliterate(tt.text, tt.language)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, GSM7, Analyze(result).Encoding)
+			}
+		})
+	}
+}
+
+func TestSupportedLanguage(t *testing.T) {
+	assert.True(t, SupportedLanguage("tr"))
+	assert.True(t, SupportedLanguage("RO"))
+	assert.False(t, SupportedLanguage("xx"))
+	assert.False(t, SupportedLanguage(""))
+}
+
+func TestTransliterationsProduceGSM(t *testing.T) {
+	// Tablolar yalnızca GSM dışı karakterleri GSM karakterlerine çevirmeli
+	for language, table := range transliterations {
+		for r, replacement := range table {
+			assert.False(t, IsGSM(r), language+" "+string(r))
+			assert.Equal(t, GSM7, Analyze(replacement).Encoding, language+" "+string(r))
+		}
+	}
+	for r, replacement := range punctuation {
+		assert.False(t, IsGSM(r), string(r))
+		assert.Equal(t, GSM7, Analyze(replacement).Encoding, string(r))
+	}
+}